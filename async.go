@@ -0,0 +1,297 @@
+package richerror
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// OverflowPolicy controls what an AsyncLogger does when its queue is full
+type OverflowPolicy uint8
+
+const (
+	// DropNewest drops the entry that is being logged
+	DropNewest OverflowPolicy = iota
+	// DropOldest drops the oldest queued entry to make room for the entry that is being logged
+	DropOldest
+	// Block waits for room in the queue, the entry is dropped if the queue is still full after BlockTimeout
+	Block
+)
+
+// AsyncLoggerOptions configures an AsyncLogger, zero values are replaced by their defaults
+type AsyncLoggerOptions struct {
+	// QueueSize is the maximum number of queued entries, 1024 by default
+	QueueSize int
+	// Workers is the number of goroutines that log the queued entries, 1 by default
+	Workers int
+	// Overflow is the policy applied when the queue is full, DropNewest by default
+	Overflow OverflowPolicy
+	// BlockTimeout is how long the Block policy waits for room in the queue, 100ms by default
+	BlockTimeout time.Duration
+	// FatalFlushTimeout is how long logging a Fatal error waits for the queued entries to be logged, 5s by default
+	FatalFlushTimeout time.Duration
+}
+
+// AsyncLoggerStats holds the counters of an AsyncLogger
+type AsyncLoggerStats struct {
+	// Queued is the number of entries waiting to be logged
+	Queued int
+	// DroppedNewest is the number of entries dropped by the DropNewest policy
+	DroppedNewest uint64
+	// DroppedOldest is the number of entries dropped by the DropOldest policy
+	DroppedOldest uint64
+	// DroppedTimeout is the number of entries dropped by the Block policy
+	DroppedTimeout uint64
+	// Panicked is the number of entries whose logging has panicked, the panics are recovered so workers keep running
+	Panicked uint64
+}
+
+// Dropped returns the total number of dropped entries
+func (s AsyncLoggerStats) Dropped() uint64 {
+	return s.DroppedNewest + s.DroppedOldest + s.DroppedTimeout
+}
+
+type asyncEntryType uint8
+
+const (
+	asyncError asyncEntryType = iota
+	asyncInfo
+	asyncInfoWithMetadata
+	asyncInfoCtx
+)
+
+type asyncEntry struct {
+	_type    asyncEntryType
+	ctx      context.Context
+	err      error
+	msg      string
+	metadata []interface{}
+}
+
+// Assert AsyncLogger implements ContextErrorLogger and ContextInfoLogger
+var _ ContextErrorLogger = &AsyncLogger{}
+var _ ContextInfoLogger = &AsyncLogger{}
+
+// AsyncLogger is an ErrorLogger that queues the entries and logs them using the wrapped logger in background
+// goroutines, so slow loggers (e.g. SentryLogger) don't add latency to failed requests. Fatal errors bypass the
+// queue: the queued entries are flushed and the error is logged synchronously, as the wrapped logger may exit.
+//
+// Keep in mind that the context given to LogCtx is used after the request may have finished, so loggers that need a
+// live context (e.g. OpenTelemetryLogger, which records errors on the active span) should not be wrapped.
+type AsyncLogger struct {
+	logger  ErrorLogger
+	options AsyncLoggerOptions
+
+	queue   chan asyncEntry
+	workers sync.WaitGroup
+
+	// closeMu guards queue against being closed while entries are being queued
+	closeMu sync.RWMutex
+	closed  bool
+
+	mu      sync.Mutex
+	pending int
+	idle    chan struct{}
+	stats   AsyncLoggerStats
+}
+
+// NewAsyncLogger returns an AsyncLogger that logs using the given logger and starts its workers. Close must be called
+// to stop the workers.
+func NewAsyncLogger(logger ErrorLogger, options AsyncLoggerOptions) *AsyncLogger {
+	if options.QueueSize <= 0 {
+		options.QueueSize = 1024
+	}
+	if options.Workers <= 0 {
+		options.Workers = 1
+	}
+	if options.BlockTimeout <= 0 {
+		options.BlockTimeout = 100 * time.Millisecond
+	}
+	if options.FatalFlushTimeout <= 0 {
+		options.FatalFlushTimeout = 5 * time.Second
+	}
+
+	a := &AsyncLogger{
+		logger:  logger,
+		options: options,
+		queue:   make(chan asyncEntry, options.QueueSize),
+	}
+
+	a.workers.Add(options.Workers)
+	for i := 0; i < options.Workers; i++ {
+		go a.work()
+	}
+
+	return a
+}
+
+func (a *AsyncLogger) Log(err error) {
+	a.LogCtx(context.Background(), err)
+}
+
+func (a *AsyncLogger) LogCtx(ctx context.Context, err error) {
+	var rErr RichError
+	if errors.As(err, &rErr) && rErr.Level() == Fatal {
+		flushCtx, cancel := context.WithTimeout(context.Background(), a.options.FatalFlushTimeout)
+		_ = a.Flush(flushCtx)
+		cancel()
+
+		LogCtx(a.logger, ctx, err)
+		return
+	}
+
+	a.enqueue(asyncEntry{_type: asyncError, ctx: ctx, err: err})
+}
+
+func (a *AsyncLogger) LogInfo(msg string) {
+	a.enqueue(asyncEntry{_type: asyncInfo, msg: msg})
+}
+
+func (a *AsyncLogger) LogInfoWithMetadata(msg string, metadata ...interface{}) {
+	a.enqueue(asyncEntry{_type: asyncInfoWithMetadata, msg: msg, metadata: metadata})
+}
+
+func (a *AsyncLogger) LogInfoCtx(ctx context.Context, msg string, metadata ...interface{}) {
+	a.enqueue(asyncEntry{_type: asyncInfoCtx, ctx: ctx, msg: msg, metadata: metadata})
+}
+
+// Flush blocks until every queued entry has been logged, or ctx is done
+func (a *AsyncLogger) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	if a.pending == 0 {
+		a.mu.Unlock()
+		return nil
+	}
+	if a.idle == nil {
+		a.idle = make(chan struct{})
+	}
+	idle := a.idle
+	a.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close logs the queued entries and stops the workers. Entries logged after Close are logged synchronously.
+func (a *AsyncLogger) Close() {
+	a.closeMu.Lock()
+	if a.closed {
+		a.closeMu.Unlock()
+		return
+	}
+	a.closed = true
+	close(a.queue)
+	a.closeMu.Unlock()
+
+	a.workers.Wait()
+}
+
+// Stats returns the current counters of the logger
+func (a *AsyncLogger) Stats() AsyncLoggerStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stats := a.stats
+	stats.Queued = a.pending
+	return stats
+}
+
+func (a *AsyncLogger) enqueue(entry asyncEntry) {
+	a.closeMu.RLock()
+	defer a.closeMu.RUnlock()
+
+	if a.closed {
+		a.log(entry)
+		return
+	}
+
+	a.mu.Lock()
+	a.pending++
+	a.mu.Unlock()
+
+	select {
+	case a.queue <- entry:
+		return
+	default:
+	}
+
+	switch a.options.Overflow {
+	case DropOldest:
+		for {
+			select {
+			case a.queue <- entry:
+				return
+			default:
+			}
+
+			select {
+			case <-a.queue:
+				a.done(func(stats *AsyncLoggerStats) { stats.DroppedOldest++ })
+			default:
+			}
+		}
+	case Block:
+		timer := time.NewTimer(a.options.BlockTimeout)
+		defer timer.Stop()
+
+		select {
+		case a.queue <- entry:
+		case <-timer.C:
+			a.done(func(stats *AsyncLoggerStats) { stats.DroppedTimeout++ })
+		}
+	default:
+		a.done(func(stats *AsyncLoggerStats) { stats.DroppedNewest++ })
+	}
+}
+
+func (a *AsyncLogger) work() {
+	defer a.workers.Done()
+
+	for entry := range a.queue {
+		a.log(entry)
+		a.done(nil)
+	}
+}
+
+// log logs the entry using the wrapped logger, a panicking logger doesn't stop the worker but is counted (see Stats)
+func (a *AsyncLogger) log(entry asyncEntry) {
+	defer func() {
+		if recover() != nil {
+			a.mu.Lock()
+			a.stats.Panicked++
+			a.mu.Unlock()
+		}
+	}()
+
+	switch entry._type {
+	case asyncInfo:
+		a.logger.LogInfo(entry.msg)
+	case asyncInfoWithMetadata:
+		a.logger.LogInfoWithMetadata(entry.msg, entry.metadata...)
+	case asyncInfoCtx:
+		LogInfoCtx(a.logger, entry.ctx, entry.msg, entry.metadata...)
+	default:
+		LogCtx(a.logger, entry.ctx, entry.err)
+	}
+}
+
+// done marks a queued entry as handled (logged or dropped), count updates the counters if the entry has been dropped
+func (a *AsyncLogger) done(count func(*AsyncLoggerStats)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if count != nil {
+		count(&a.stats)
+	}
+
+	a.pending--
+	if a.pending == 0 && a.idle != nil {
+		close(a.idle)
+		a.idle = nil
+	}
+}
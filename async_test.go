@@ -0,0 +1,219 @@
+package richerror
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedLogger is a recordingLogger whose Log calls block until the gate is opened, so the queue of an AsyncLogger can
+// be filled deterministically
+type gatedLogger struct {
+	recordingLogger
+	started chan struct{}
+	gate    chan struct{}
+}
+
+func newGatedLogger() *gatedLogger {
+	return &gatedLogger{started: make(chan struct{}, 100), gate: make(chan struct{})}
+}
+
+func (g *gatedLogger) Log(err error) {
+	g.started <- struct{}{}
+	<-g.gate
+	g.recordingLogger.Log(err)
+}
+
+func (g *gatedLogger) Open() {
+	close(g.gate)
+}
+
+// newFullAsyncLogger returns an AsyncLogger whose single worker is blocked logging "first" and whose queue (of size 2)
+// holds "second" and "third"
+func newFullAsyncLogger(t *testing.T, options AsyncLoggerOptions) (*AsyncLogger, *gatedLogger) {
+	t.Helper()
+
+	logger := newGatedLogger()
+	options.QueueSize = 2
+	options.Workers = 1
+	async := NewAsyncLogger(logger, options)
+
+	async.Log(New("first"))
+	<-logger.started
+	async.Log(New("second"))
+	async.Log(New("third"))
+
+	return async, logger
+}
+
+func flush(t *testing.T, async *AsyncLogger) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := async.Flush(ctx); err != nil {
+		t.Fatalf("expected the queue to be flushed, got %v", err)
+	}
+}
+
+func TestAsyncLoggerDropNewest(t *testing.T) {
+	async, logger := newFullAsyncLogger(t, AsyncLoggerOptions{Overflow: DropNewest})
+	defer async.Close()
+
+	async.Log(New("fourth"))
+	logger.Open()
+	flush(t, async)
+
+	assertMessages(t, logger.Messages(), "first", "second", "third")
+	if stats := async.Stats(); stats.DroppedNewest != 1 || stats.Dropped() != 1 || stats.Queued != 0 {
+		t.Errorf("expected a single entry dropped by DropNewest, got %+v", stats)
+	}
+}
+
+func TestAsyncLoggerDropOldest(t *testing.T) {
+	async, logger := newFullAsyncLogger(t, AsyncLoggerOptions{Overflow: DropOldest})
+	defer async.Close()
+
+	async.Log(New("fourth"))
+	logger.Open()
+	flush(t, async)
+
+	assertMessages(t, logger.Messages(), "first", "third", "fourth")
+	if stats := async.Stats(); stats.DroppedOldest != 1 || stats.Dropped() != 1 {
+		t.Errorf("expected a single entry dropped by DropOldest, got %+v", stats)
+	}
+}
+
+func TestAsyncLoggerBlockDropsAfterTimeout(t *testing.T) {
+	async, logger := newFullAsyncLogger(t, AsyncLoggerOptions{Overflow: Block, BlockTimeout: 10 * time.Millisecond})
+	defer async.Close()
+
+	async.Log(New("fourth"))
+	logger.Open()
+	flush(t, async)
+
+	assertMessages(t, logger.Messages(), "first", "second", "third")
+	if stats := async.Stats(); stats.DroppedTimeout != 1 || stats.Dropped() != 1 {
+		t.Errorf("expected a single entry dropped by Block, got %+v", stats)
+	}
+}
+
+func TestAsyncLoggerBlockWaitsForRoom(t *testing.T) {
+	async, logger := newFullAsyncLogger(t, AsyncLoggerOptions{Overflow: Block, BlockTimeout: 5 * time.Second})
+	defer async.Close()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		logger.Open()
+	}()
+	async.Log(New("fourth"))
+	flush(t, async)
+
+	assertMessages(t, logger.Messages(), "first", "second", "third", "fourth")
+	if stats := async.Stats(); stats.Dropped() != 0 {
+		t.Errorf("expected no entry to be dropped, got %+v", stats)
+	}
+}
+
+func TestAsyncLoggerFlushHonorsContext(t *testing.T) {
+	async, logger := newFullAsyncLogger(t, AsyncLoggerOptions{})
+	defer async.Close()
+	defer logger.Open()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := async.Flush(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected Flush to return when its context is done, got %v", err)
+	}
+	if stats := async.Stats(); stats.Queued != 3 {
+		t.Errorf("expected 3 queued entries, got %+v", stats)
+	}
+}
+
+func TestAsyncLoggerCloseLogsQueuedEntries(t *testing.T) {
+	async, logger := newFullAsyncLogger(t, AsyncLoggerOptions{})
+
+	logger.Open()
+	async.Close()
+	assertMessages(t, logger.Messages(), "first", "second", "third")
+
+	async.Log(New("after close"))
+	assertMessages(t, logger.Messages(), "first", "second", "third", "after close")
+}
+
+func TestAsyncLoggerFatalBypassesQueue(t *testing.T) {
+	logger := &recordingLogger{}
+	async := NewAsyncLogger(logger, AsyncLoggerOptions{})
+	defer async.Close()
+
+	async.Log(New("first"))
+	async.Log(New("second"))
+	async.Log(New("fatal").WithLevel(Fatal))
+
+	assertMessages(t, logger.Messages(), "first", "second", "fatal")
+}
+
+// panickingLogger is a recordingLogger that panics when asked to log an error whose message is "panic"
+type panickingLogger struct {
+	recordingLogger
+}
+
+func (p *panickingLogger) Log(err error) {
+	if err.(*richError).message == "panic" {
+		panic("logger failed")
+	}
+
+	p.recordingLogger.Log(err)
+}
+
+func TestAsyncLoggerCountsPanics(t *testing.T) {
+	logger := &panickingLogger{}
+	async := NewAsyncLogger(logger, AsyncLoggerOptions{})
+	defer async.Close()
+
+	async.Log(New("panic"))
+	async.Log(New("after panic"))
+	flush(t, async)
+
+	assertMessages(t, logger.Messages(), "after panic")
+	if stats := async.Stats(); stats.Panicked != 1 {
+		t.Errorf("expected a single panic, got %+v", stats)
+	}
+}
+
+func TestAsyncLoggerConcurrentLogging(t *testing.T) {
+	policies := map[string]OverflowPolicy{"DropNewest": DropNewest, "DropOldest": DropOldest, "Block": Block}
+	for name, overflow := range policies {
+		t.Run(name, func(t *testing.T) {
+			logger := &recordingLogger{}
+			async := NewAsyncLogger(logger, AsyncLoggerOptions{
+				QueueSize: 4, Workers: 2, Overflow: overflow, BlockTimeout: time.Millisecond,
+			})
+
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; j < 50; j++ {
+						async.Log(New(fmt.Sprintf("error %d-%d", i, j)))
+					}
+				}(i)
+			}
+			wg.Wait()
+			async.Close()
+
+			stats := async.Stats()
+			if logged := uint64(len(logger.Messages())); logged+stats.Dropped() != 1000 {
+				t.Errorf("expected every entry to be either logged or dropped, got %d logged and %+v", logged, stats)
+			}
+			if stats.Queued != 0 {
+				t.Errorf("expected no queued entry after Close, got %+v", stats)
+			}
+		})
+	}
+}
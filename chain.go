@@ -0,0 +1,88 @@
+package richerror
+
+import (
+	"fmt"
+)
+
+// ErrorFrame describes a single layer of the causal chain of an error
+type ErrorFrame struct {
+	// Parent is the index of the frame that wraps this frame, it's -1 for the outermost error
+	Parent int `json:"parent"`
+
+	Message   string    `json:"message"`
+	Operation Operation `json:"operation,omitempty"`
+	Level     Level     `json:"level,omitempty"`
+	Kind      Kind      `json:"kind,omitempty"`
+	Type      string    `json:"type,omitempty"`
+	Fields    Metadata  `json:"fields,omitempty"`
+
+	// RuntimeInfo is the runtime information of where the layer has been created, only RichErrors have it
+	RuntimeInfo *RuntimeInfo `json:"runtime_info,omitempty"`
+
+	// StackTrace is the stack trace captured when the layer has been created, only RichErrors have it
+	StackTrace []RuntimeInfo `json:"stack_trace,omitempty"`
+
+	// GoType is the Go type of the layer (e.g. *net.OpError), it's empty for RichErrors
+	GoType string `json:"go_type,omitempty"`
+}
+
+// ErrorChain returns every layer of the causal chain of the given error in depth-first order. Both single errors
+// (Unwrap() error) and multi-errors (Unwrap() []error) are followed, so each frame has to point to its parent frame.
+// Messages of RichError frames only contain the message of the layer itself, while other errors keep their whole
+// message as they usually embed the message of their wrapped errors.
+func ErrorChain(err error) []ErrorFrame {
+	return appendErrorFrames(nil, err, -1)
+}
+
+func appendErrorFrames(frames []ErrorFrame, err error, parent int) []ErrorFrame {
+	if err == nil {
+		return frames
+	}
+
+	// causes of aggregated errors are attached directly to the aggregated error
+	if list, ok := err.(*errorList); ok {
+		for _, wrappedError := range list.errs {
+			frames = appendErrorFrames(frames, wrappedError, parent)
+		}
+
+		return frames
+	}
+
+	frame := ErrorFrame{Parent: parent}
+
+	switch e := err.(type) {
+	case *richError:
+		frame.Message = e.message
+		frame.Operation = e.operation
+		frame.Level = e.level
+		frame.Kind = e.kind
+		frame.Fields = e.fields
+		if e._type != nil {
+			frame.Type = e._type.String()
+		}
+		if len(e.runtimeInfo) != 0 {
+			frame.RuntimeInfo = &e.runtimeInfo[0]
+		}
+		frame.StackTrace = e.StackTrace()
+	case *simpleError:
+		frame.Message = e.Message
+		frame.GoType = e.GoType
+	default:
+		frame.Message = err.Error()
+		frame.GoType = fmt.Sprintf("%T", err)
+	}
+
+	frames = append(frames, frame)
+	index := len(frames) - 1
+
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, wrappedError := range e.Unwrap() {
+			frames = appendErrorFrames(frames, wrappedError, index)
+		}
+	case interface{ Unwrap() error }:
+		frames = appendErrorFrames(frames, e.Unwrap(), index)
+	}
+
+	return frames
+}
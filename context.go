@@ -0,0 +1,137 @@
+package richerror
+
+import (
+	"context"
+	"strings"
+)
+
+// DefaultContextHeaders maps the request headers (or gRPC metadata keys) that the interceptors store in the request
+// context to their field names. The trace ID is also extracted from the W3C traceparent header if present.
+var DefaultContextHeaders = map[string]string{
+	"X-Request-Id": "request_id",
+	"X-User-Id":    "user_id",
+	"X-Trace-Id":   "trace_id",
+}
+
+type contextFieldsKey struct{}
+
+// ContextWithFields returns a copy of ctx that carries the given fields along with the fields already stored in ctx.
+// Errors created using NewCtx or WithContext, and errors logged using LogCtx will include these fields.
+func ContextWithFields(ctx context.Context, fields Metadata) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+
+	existingFields := FieldsFromContext(ctx)
+	merged := make(Metadata, len(existingFields)+len(fields))
+	for key, value := range existingFields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+
+	return context.WithValue(ctx, contextFieldsKey{}, merged)
+}
+
+// FieldsFromContext returns the fields stored in ctx using ContextWithFields, the result must not be modified
+func FieldsFromContext(ctx context.Context) Metadata {
+	if ctx == nil {
+		return nil
+	}
+
+	fields, _ := ctx.Value(contextFieldsKey{}).(Metadata)
+	return fields
+}
+
+// NewCtx creates a new richError that includes the fields stored in ctx
+func NewCtx(ctx context.Context, message string) *richError {
+	return newRichError(message, 1).WithFields(FieldsFromContext(ctx))
+}
+
+// WithContext appends the fields stored in ctx to the error, fields that already exist are not overwritten
+func (r *richError) WithContext(ctx context.Context) *richError {
+	r = r.mutable()
+	for key, value := range FieldsFromContext(ctx) {
+		if _, ok := r.fields[key]; !ok {
+			r.fields[key] = value
+		}
+	}
+	return r
+}
+
+// ContextErrorLogger is an ErrorLogger that can log errors along with the fields stored in a context
+type ContextErrorLogger interface {
+	ErrorLogger
+	LogCtx(context.Context, error)
+}
+
+// LogCtx logs the error using the given logger, if the logger is a ContextErrorLogger the fields stored in ctx will be
+// logged as well
+func LogCtx(logger ErrorLogger, ctx context.Context, err error) {
+	if ctxLogger, ok := logger.(ContextErrorLogger); ok {
+		ctxLogger.LogCtx(ctx, err)
+		return
+	}
+
+	logger.Log(err)
+}
+
+// ContextInfoLogger is an ErrorLogger that can log info messages along with a context, e.g. SentryLogger records them
+// as breadcrumbs of the request the context belongs to
+type ContextInfoLogger interface {
+	ErrorLogger
+	LogInfoCtx(ctx context.Context, msg string, metadata ...interface{})
+}
+
+// LogInfoCtx logs the info message and the given key value pairs using the given logger, if the logger is a
+// ContextInfoLogger ctx is given to it as well
+func LogInfoCtx(logger ErrorLogger, ctx context.Context, msg string, metadata ...interface{}) {
+	if ctxLogger, ok := logger.(ContextInfoLogger); ok {
+		ctxLogger.LogInfoCtx(ctx, msg, metadata...)
+		return
+	}
+
+	logger.LogInfoWithMetadata(msg, metadata...)
+}
+
+// mergeFields returns the metadata of the error along with the fields of the context, metadata of the error wins
+func mergeFields(metadata Metadata, contextFields Metadata) Metadata {
+	if len(contextFields) == 0 {
+		return metadata
+	}
+
+	merged := make(Metadata, len(metadata)+len(contextFields))
+	for key, value := range contextFields {
+		merged[key] = value
+	}
+	for key, value := range metadata {
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// fieldsFromHeaders returns the fields that should be stored in the request context based on the given headers,
+// get returns the value of a header
+func fieldsFromHeaders(headers map[string]string, get func(string) string) Metadata {
+	if headers == nil {
+		headers = DefaultContextHeaders
+	}
+
+	fields := make(Metadata)
+	for header, field := range headers {
+		if value := get(header); value != "" {
+			fields[field] = value
+		}
+	}
+
+	// traceparent: version-trace_id-parent_id-flags
+	if _, ok := fields["trace_id"]; !ok {
+		if parts := strings.Split(get("Traceparent"), "-"); len(parts) == 4 {
+			fields["trace_id"] = parts[1]
+		}
+	}
+
+	return fields
+}
@@ -1,5 +1,7 @@
 package richerror
 
+import "time"
+
 // RichError is a richer type of error that holds runtime information with itself
 type RichError interface {
 	String() string
@@ -31,3 +33,26 @@ type Operation string
 
 // Metadata stores metadata of error
 type Metadata map[string]interface{}
+
+// StackTraceError is implemented by errors that may have captured the full stack trace of where they were created, use
+// errors.As to access it
+type StackTraceError interface {
+	StackTrace() []RuntimeInfo
+}
+
+// FieldViolationsError is implemented by errors that describe the bad fields of a request, use errors.As to access it
+type FieldViolationsError interface {
+	FieldViolations() []FieldViolation
+}
+
+// RetryDelayError is implemented by errors that hint how long the caller should wait before retrying, use errors.As to
+// access it
+type RetryDelayError interface {
+	RetryDelay() time.Duration
+}
+
+// FieldViolation describes a single bad field of a request, it's usually used along with InvalidArgument kind
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
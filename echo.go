@@ -1,7 +1,9 @@
 package richerror
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/labstack/echo/v4/middleware"
@@ -9,19 +11,54 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// EchoMiddleware is a helper that provides echo middlewares that will catch and log errors of your echo server. If
+// your handlers return RichError it will set the http status code based on their Kind.
+type EchoMiddleware struct {
+	Logger ErrorLogger
+
+	// ContextHeaders maps the request headers that will be stored in the request context to their field names (see
+	// ContextWithFields), if not provided DefaultContextHeaders will be used
+	ContextHeaders map[string]string
+}
+
+// GetEchoLoggerMiddleware returns an echo middleware that recovers panics, logs errors using the given logger and
+// returns an http error with a status code that matches the Kind of the error. It stores the fields described by
+// DefaultContextHeaders in the request context (see ContextWithFields).
 func GetEchoLoggerMiddleware(logger ErrorLogger) echo.MiddlewareFunc {
+	return EchoMiddleware{Logger: logger}.LoggerMiddleware()
+}
+
+// GetEchoProblemMiddleware works like GetEchoLoggerMiddleware but writes the error response as RFC 7807 (RFC 9457)
+// problem details using the given renderer.
+func GetEchoProblemMiddleware(logger ErrorLogger, renderer ProblemRenderer) echo.MiddlewareFunc {
+	return EchoMiddleware{Logger: logger}.ProblemMiddleware(renderer)
+}
+
+// LoggerMiddleware returns an echo middleware that recovers panics, logs errors using the Logger and returns an http
+// error with a status code that matches the Kind of the error. It stores the fields described by ContextHeaders in the
+// request context.
+func (m EchoMiddleware) LoggerMiddleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		middleware.Recover()
 		return func(c echo.Context) (err error) {
+			ctx := m.seedContext(c)
+
 			defer func() {
-				if e := recoverAndReturnError(c.Path()); e != nil {
-					logger.Log(e)
+				if e := httpErrorFromPanic(c.Path(), recover()); e != nil {
+					m.log(ctx, e)
 					c.Error(e)
 				}
 			}()
 
 			if err := next(c); err != nil {
-				logger.Log(err)
+				m.log(ctx, err)
+
+				var rErr RichError
+				var httpErr *echo.HTTPError
+				if !errors.As(err, &rErr) && errors.As(err, &httpErr) {
+					return httpErr
+				}
+
 				code, msg := getErrorStatusCodeAndMessage(err)
 				return echo.NewHTTPError(code, msg)
 			}
@@ -31,16 +68,65 @@ func GetEchoLoggerMiddleware(logger ErrorLogger) echo.MiddlewareFunc {
 	}
 }
 
-func getErrorStatusCodeAndMessage(err error) (int, string) {
-	var rErr RichError
-	if errors.As(err, &rErr) {
-		message := err.Error()
-		if rErr.Type() != nil {
-			message = rErr.Type().String()
+// ProblemMiddleware works like LoggerMiddleware but writes the error response as RFC 7807 (RFC 9457) problem details
+// using the given renderer.
+func (m EchoMiddleware) ProblemMiddleware(renderer ProblemRenderer) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			ctx := m.seedContext(c)
+
+			defer func() {
+				if e := httpErrorFromPanic(c.Path(), recover()); e != nil {
+					m.log(ctx, e)
+					writeProblem(c.Response(), echoProblem(renderer, c.Request(), e))
+				}
+			}()
+
+			if err := next(c); err != nil {
+				m.log(ctx, err)
+				writeProblem(c.Response(), echoProblem(renderer, c.Request(), err))
+			}
+
+			return nil
 		}
+	}
+}
 
-		return rErr.Kind().HttpStatusCode(), message
+func (m EchoMiddleware) log(ctx context.Context, err error) {
+	if m.Logger != nil {
+		LogCtx(m.Logger, ctx, err)
 	}
+}
+
+// echoProblem returns the problem details describing the given error, echo.HTTPErrors (e.g. the ones returned for
+// unknown routes) keep their status code and message
+func echoProblem(renderer ProblemRenderer, r *http.Request, err error) Problem {
+	problem := renderer.Problem(r, err)
+
+	var rErr RichError
+	var httpErr *echo.HTTPError
+	if errors.As(err, &rErr) || !errors.As(err, &httpErr) {
+		return problem
+	}
+
+	problem.Status = httpErr.Code
+	problem.Title = http.StatusText(httpErr.Code)
+	if !renderer.HideDetail {
+		problem.Detail = fmt.Sprint(httpErr.Message)
+	}
+
+	return problem
+}
+
+// seedContext stores the fields described by ContextHeaders, the IDs of the active span (see ContextWithSpanFields),
+// the route and a sentry hub scoped to the request in the request context and returns the context
+func (m EchoMiddleware) seedContext(c echo.Context) context.Context {
+	req := c.Request()
+	ctx := ContextWithFields(req.Context(), fieldsFromHeaders(m.ContextHeaders, req.Header.Get))
+	ctx = ContextWithSpanFields(ctx)
+	ctx = contextWithRoute(ctx, c.Path())
+	ctx = contextWithSentryHTTPHub(ctx, req, c.RealIP(), c.Path())
+	c.SetRequest(req.WithContext(ctx))
 
-	return http.StatusInternalServerError, err.Error()
+	return ctx
 }
@@ -0,0 +1,107 @@
+package richerror
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Assert richError implements fmt.Formatter
+var _ fmt.Formatter = &richError{}
+
+// Format implements fmt.Formatter. %s and %v print the message chain (same as Error), %q prints it quoted, %+v prints
+// every layer of the causal chain along with its operation, level, kind, type, fields, runtime info and stack trace,
+// and %#v prints a Go-syntax representation of the error for debugging purposes.
+func (r *richError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			_, _ = io.WriteString(s, r.detailedString())
+			return
+		}
+
+		if s.Flag('#') {
+			_, _ = io.WriteString(s, r.goString())
+			return
+		}
+
+		_, _ = io.WriteString(s, r.Error())
+	case 's':
+		_, _ = io.WriteString(s, r.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", r.Error())
+	default:
+		_, _ = fmt.Fprintf(s, "%%!%c(%s)", verb, r.Error())
+	}
+}
+
+// detailedString returns every layer of the causal chain (see ErrorChain) indented by its depth
+func (r *richError) detailedString() string {
+	frames := ErrorChain(r)
+	depths := make([]int, len(frames))
+
+	var b strings.Builder
+	for i, frame := range frames {
+		if frame.Parent >= 0 {
+			depths[i] = depths[frame.Parent] + 1
+		}
+
+		indent := strings.Repeat("\t", depths[i])
+		if i == 0 {
+			b.WriteString(frame.Message)
+		} else {
+			b.WriteString("\n" + indent + "caused by: " + frame.Message)
+		}
+
+		if frame.GoType != "" {
+			fmt.Fprintf(&b, " (%s)", frame.GoType)
+		}
+
+		var attributes []string
+		if frame.Operation != "" {
+			attributes = append(attributes, fmt.Sprintf("operation: %s", frame.Operation))
+		}
+		if frame.Level != UnknownLevel {
+			attributes = append(attributes, fmt.Sprintf("level: %s", frame.Level))
+		}
+		if frame.Kind != UnknownKind {
+			attributes = append(attributes, fmt.Sprintf("kind: %s", frame.Kind))
+		}
+		if frame.Type != "" {
+			attributes = append(attributes, fmt.Sprintf("type: %s", frame.Type))
+		}
+		if len(attributes) != 0 {
+			b.WriteString("\n" + indent + "\t" + strings.Join(attributes, " "))
+		}
+
+		if len(frame.Fields) != 0 {
+			fmt.Fprintf(&b, "\n%s\tfields: %+v", indent, frame.Fields)
+		}
+
+		if frame.RuntimeInfo != nil {
+			b.WriteString("\n" + indent + "\t" + frame.RuntimeInfo.String())
+		}
+
+		if len(frame.StackTrace) != 0 {
+			b.WriteString("\n" + indent + "\tstack trace:")
+		}
+		for _, info := range frame.StackTrace {
+			fmt.Fprintf(&b, "\n%s\t\t%s\n%s\t\t\t%s:%d", indent, info.FunctionName, indent, info.FileName, info.LineNumber)
+		}
+	}
+
+	return b.String()
+}
+
+// goString returns a Go-syntax representation of the error
+func (r *richError) goString() string {
+	var _type interface{}
+	if r._type != nil {
+		_type = r._type
+	}
+
+	return fmt.Sprintf("&richerror.richError{message:%q, operation:%q, level:%q, kind:%q, type:%#v, fields:%#v, "+
+		"runtimeInfo:%#v, fieldViolations:%#v, retryDelay:%d, wrappedError:%#v}",
+		r.message, r.operation, r.level.String(), r.kind.String(), _type, r.fields,
+		r.runtimeInfo, r.fieldViolations, r.retryDelay, r.wrappedError)
+}
@@ -0,0 +1,124 @@
+package richerror
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// GroupMode controls how a Group reacts to the errors of its goroutines
+type GroupMode uint8
+
+const (
+	// CollectAll waits for every goroutine and collects all of their errors
+	CollectAll GroupMode = iota
+	// StopOnFirstError cancels the context of the group as soon as a goroutine fails, errors caused by the cancellation
+	// (i.e. whose Kind is Canceled) are dropped
+	StopOnFirstError
+)
+
+// Group is a collection of goroutines working on subtasks of a common task, similar to errgroup.Group. Errors of the
+// goroutines are turned into RichErrors tagged with the label and index of the goroutine, and panics are recovered
+// and turned into errors instead of crashing the process. Wait returns all errors aggregated in a single RichError.
+// The zero Group is valid, works in CollectAll mode and doesn't cancel anything.
+type Group struct {
+	mode   GroupMode
+	cancel func()
+
+	wg sync.WaitGroup
+
+	mu    sync.Mutex
+	count int
+	errs  map[int]error
+	// failed reports whether a goroutine has failed, in StopOnFirstError mode the group context is canceled then
+	failed bool
+}
+
+// NewGroup returns a new Group and a context derived from ctx. The derived context is canceled when Wait returns, or
+// in StopOnFirstError mode, when the first goroutine fails.
+func NewGroup(ctx context.Context, mode GroupMode) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{mode: mode, cancel: cancel}, ctx
+}
+
+// Go calls the given function in a new goroutine, label is used to identify the goroutine in the resulting errors
+func (g *Group) Go(label string, f func() error) {
+	callSite := callerRuntimeInfo(1)
+
+	g.mu.Lock()
+	index := g.count
+	g.count++
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer func() {
+			if e := errorFromPanic(label, recover()); e != nil {
+				g.fail(index, e.WithFields(goroutineMetadata(label, index)))
+			}
+		}()
+
+		if err := f(); err != nil {
+			rErr := New(fmt.Sprintf("goroutine %s failed", label)).
+				WithError(err).
+				WithFields(goroutineMetadata(label, index))
+			rErr.runtimeInfo[0] = callSite
+
+			g.fail(index, rErr)
+		}
+	}()
+}
+
+// Wait blocks until all goroutines of the group have returned, then returns their errors aggregated in a single
+// RichError (see Aggregate), or nil if none of them has failed
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel()
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.errs) == 0 {
+		return nil
+	}
+
+	errs := make([]error, 0, len(g.errs))
+	for i := 0; i < g.count; i++ {
+		if err, ok := g.errs[i]; ok {
+			errs = append(errs, err)
+		}
+	}
+
+	return newRichError(fmt.Sprintf("%d of %d goroutines failed", len(errs), g.count), 1).WithErrors(errs...)
+}
+
+// fail records the error of a goroutine. In StopOnFirstError mode, cancellation errors that happen after the first
+// failure are dropped, as they're caused by the cancellation of the group context rather than a failure of their own.
+func (g *Group) fail(index int, err error) {
+	g.mu.Lock()
+	if g.mode == StopOnFirstError && g.failed && KindFromError(err) == Canceled {
+		g.mu.Unlock()
+		return
+	}
+
+	if g.errs == nil {
+		g.errs = make(map[int]error)
+	}
+	g.errs[index] = err
+	g.failed = true
+	g.mu.Unlock()
+
+	if g.mode == StopOnFirstError && g.cancel != nil {
+		g.cancel()
+	}
+}
+
+func goroutineMetadata(label string, index int) Metadata {
+	return Metadata{
+		"goroutine_label": label,
+		"goroutine_index": index,
+	}
+}
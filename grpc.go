@@ -7,29 +7,44 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
 // GRPCInterceptors is a helper that provides unary and stream grpc interceptors that will catch and log errors
 // of your grpc server. If your grpc services return RichError it will set the grpc status code based on their Kind.
 // Keep in mind that these interceptors will not log errors regarding the reflection API.
+//
+// Returned statuses carry a richerrorpb.RichError detail describing the error along with the standard google.rpc
+// ErrorInfo, BadRequest and RetryInfo details whenever the error has the data they need. DetailsMetadataKeys selects
+// which Metadata keys are sent to the caller (none by default) and DetailsRuntimeInfo controls whether the runtime
+// info chain is sent as well.
 type GRPCInterceptors struct {
 	Logger ErrorLogger
+
+	DetailsMetadataKeys []string
+	DetailsRuntimeInfo  bool
+
+	// ContextMetadataKeys maps the incoming metadata keys that will be stored in the request context to their field
+	// names (see ContextWithFields), if not provided DefaultContextHeaders will be used
+	ContextMetadataKeys map[string]string
 }
 
 // UnaryInterceptor returns a gRPC unary interceptor that intercepts every gRPC request and in case of error prints
 // (or logs) error and sets the grpc status code according to the error Kind. It also recovers panics.
 func (h GRPCInterceptors) UnaryInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		ctx = h.seedContext(ctx, info.FullMethod)
+
 		defer func() {
-			if e := recoverAndReturnError(info.FullMethod); e != nil {
-				h.log(info.FullMethod, e)
+			if e := errorFromPanic(info.FullMethod, recover()); e != nil {
+				h.log(ctx, info.FullMethod, e)
 				err = h.getGPRCError(e)
 			}
 		}()
 
 		if resp, err = handler(ctx, req); err != nil {
-			h.log(info.FullMethod, err)
+			h.log(ctx, info.FullMethod, err)
 			err = h.getGPRCError(err)
 			resp = nil
 		}
@@ -42,15 +57,18 @@ func (h GRPCInterceptors) UnaryInterceptor() grpc.UnaryServerInterceptor {
 // (or logs) error and sets the grpc status code according to the error Kind. It also recovers panics.
 func (h GRPCInterceptors) StreamInterceptor() grpc.StreamServerInterceptor {
 	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx := h.seedContext(stream.Context(), info.FullMethod)
+		stream = &contextServerStream{ServerStream: stream, ctx: ctx}
+
 		defer func() {
-			if e := recoverAndReturnError(info.FullMethod); e != nil {
-				h.log(info.FullMethod, e)
+			if e := errorFromPanic(info.FullMethod, recover()); e != nil {
+				h.log(ctx, info.FullMethod, e)
 				err = h.getGPRCError(e)
 			}
 		}()
 
 		if err = handler(srv, stream); err != nil {
-			h.log(info.FullMethod, err)
+			h.log(ctx, info.FullMethod, err)
 			err = h.getGPRCError(err)
 		}
 
@@ -58,24 +76,61 @@ func (h GRPCInterceptors) StreamInterceptor() grpc.StreamServerInterceptor {
 	}
 }
 
-func (GRPCInterceptors) getGPRCError(err error) error {
+// getGPRCError converts the given error into a gRPC status error. Only the Type or the message of the error itself is
+// sent, messages of the wrapped errors and errors that are not RichErrors may contain details that must not reach the
+// caller.
+func (h GRPCInterceptors) getGPRCError(err error) error {
 	var rErr RichError
-	if !errors.As(err, &rErr) {
-		message := err.Error()
+	if errors.As(err, &rErr) {
+		message := ownMessage(rErr)
 		if rErr.Type() != nil {
 			message = rErr.Type().String()
+		} else if message == "" {
+			message = rErr.Kind().String()
 		}
 
-		return status.Errorf(rErr.Kind().GRPCStatusCode(), "error: %s", message)
+		st := status.Newf(rErr.Kind().GRPCStatusCode(), "error: %s", message)
+		if detailed, e := st.WithDetails(h.details(rErr)...); e == nil {
+			st = detailed
+		}
+
+		return st.Err()
 	}
 
-	return status.Errorf(codes.Unknown, "error: %s", err.Error())
+	return status.Error(codes.Unknown, "error: unknown error")
 }
 
-func (h GRPCInterceptors) log(path string, err error) {
+func (h GRPCInterceptors) log(ctx context.Context, path string, err error) {
 	if strings.HasPrefix(path, "/grpc.reflection.v1alpha.ServerReflection/") {
 		return
 	}
 
-	h.Logger.Log(err)
+	LogCtx(h.Logger, ctx, err)
+}
+
+// seedContext stores the fields described by ContextMetadataKeys, the IDs of the active span (see
+// ContextWithSpanFields), the called method and a sentry hub scoped to the call in the request context
+func (h GRPCInterceptors) seedContext(ctx context.Context, method string) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	get := func(key string) string {
+		if values := md.Get(key); len(values) != 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	ctx = ContextWithFields(ctx, fieldsFromHeaders(h.ContextMetadataKeys, get))
+	ctx = ContextWithSpanFields(ctx)
+	ctx = contextWithSentryGRPCHub(ctx, method)
+	return contextWithRoute(ctx, method)
+}
+
+// contextServerStream is a grpc.ServerStream whose context has been replaced
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
 }
@@ -0,0 +1,77 @@
+package richerror
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryClientInterceptor returns a gRPC unary client interceptor that converts the gRPC status errors returned by
+// remote services into RichError. The Kind of the resulting error is determined by the status code and its
+// operation is set to the called method, so errors.As(err, &RichError) works across service boundaries.
+func (h GRPCInterceptors) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return h.getRichError(method, invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor returns a gRPC stream client interceptor that converts the gRPC status errors returned by
+// remote services (either on stream creation or while sending and receiving messages) into RichError.
+func (h GRPCInterceptors) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, h.getRichError(method, err)
+		}
+
+		return &richClientStream{ClientStream: stream, method: method, interceptors: h}, nil
+	}
+}
+
+// getRichError converts the given gRPC status error into a RichError, its runtime info refers to the caller of the
+// interceptor or the stream method that has called getRichError
+func (GRPCInterceptors) getRichError(method string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	rErr := fromGRPCStatus(st, 2)
+	if rErr.operation == "" {
+		rErr.operation = Operation(method)
+	}
+
+	return rErr
+}
+
+// richClientStream wraps a grpc.ClientStream and converts errors of sent and received messages into RichError
+type richClientStream struct {
+	grpc.ClientStream
+
+	method       string
+	interceptors GRPCInterceptors
+}
+
+func (s *richClientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == io.EOF {
+		return err
+	}
+
+	return s.interceptors.getRichError(s.method, err)
+}
+
+func (s *richClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == io.EOF {
+		return err
+	}
+
+	return s.interceptors.getRichError(s.method, err)
+}
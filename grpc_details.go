@@ -0,0 +1,184 @@
+package richerror
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/vortahq/rich-error/richerrorpb"
+)
+
+// FromGRPCError rebuilds a RichError from the given gRPC status error. It returns false if err is not a gRPC status
+// error. See FromGRPCStatus for more information.
+func FromGRPCError(err error) (RichError, bool) {
+	st, ok := status.FromError(err)
+	if !ok || st == nil {
+		return nil, false
+	}
+
+	return fromGRPCStatus(st, 1), true
+}
+
+// FromGRPCStatus rebuilds a RichError from the given gRPC status. If the status carries a richerrorpb.RichError
+// detail its message, kind, level, operation, type, metadata and runtime info will be restored, otherwise the
+// status message and code will be used. Standard google.rpc details are restored as well: ErrorInfo fills type,
+// operation and metadata (if not already present), BadRequest fills field violations and RetryInfo the retry delay.
+func FromGRPCStatus(st *status.Status) *richError {
+	return fromGRPCStatus(st, 1)
+}
+
+// fromGRPCStatus rebuilds a RichError from the given gRPC status, skip is the number of frames to skip above its caller
+// to reach the call site that will be recorded as the runtime info of the error
+func fromGRPCStatus(st *status.Status, skip int) *richError {
+	err := newRichError(st.Message(), skip+1).WithKind(KindFromGRPCCode(st.Code()))
+
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *richerrorpb.RichError:
+			err.fillFromProto(detail)
+		case *errdetails.ErrorInfo:
+			if err._type == nil && detail.GetReason() != "" {
+				err._type = StringType(detail.GetReason())
+			}
+
+			if err.operation == "" {
+				err.operation = Operation(detail.GetDomain())
+			}
+
+			for key, value := range detail.GetMetadata() {
+				if _, ok := err.fields[key]; !ok {
+					err.fields[key] = value
+				}
+			}
+		case *errdetails.BadRequest:
+			for _, violation := range detail.GetFieldViolations() {
+				err.WithFieldViolation(violation.GetField(), violation.GetDescription())
+			}
+		case *errdetails.RetryInfo:
+			if delay := detail.GetRetryDelay(); delay != nil {
+				err.retryDelay = delay.AsDuration()
+			}
+		}
+	}
+
+	return err
+}
+
+func (r *richError) fillFromProto(pbErr *richerrorpb.RichError) {
+	r.message = pbErr.GetMessage()
+	if kind, ok := KindByName(pbErr.GetKind()); ok && kind != UnknownKind {
+		r.kind = kind
+	}
+	r.level = levelFromString(pbErr.GetLevel())
+	r.operation = Operation(pbErr.GetOperation())
+	if pbErr.GetType() != "" {
+		r._type = StringType(pbErr.GetType())
+	}
+
+	for key, value := range pbErr.GetMetadata() {
+		r.fields[key] = value
+	}
+
+	for _, info := range pbErr.GetRuntimeInfo() {
+		r.runtimeInfo = append(r.runtimeInfo, RuntimeInfo{
+			LineNumber:   int(info.GetLineNumber()),
+			FileName:     info.GetFileName(),
+			FunctionName: info.GetFunctionName(),
+		})
+	}
+}
+
+// details returns the gRPC status details that describe the given error
+func (h GRPCInterceptors) details(err RichError) []proto.Message {
+	metadata := h.detailsMetadata(err)
+	details := []proto.Message{h.richErrorDetail(err, metadata)}
+
+	if err.Type() != nil {
+		details = append(details, &errdetails.ErrorInfo{
+			Reason:   err.Type().String(),
+			Domain:   string(err.Operation()),
+			Metadata: metadata,
+		})
+	}
+
+	var violationsErr FieldViolationsError
+	if err.Kind() == InvalidArgument && errors.As(err, &violationsErr) && len(violationsErr.FieldViolations()) != 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, violation := range violationsErr.FieldViolations() {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       violation.Field,
+				Description: violation.Description,
+			})
+		}
+
+		details = append(details, badRequest)
+	}
+
+	var retryErr RetryDelayError
+	retryable := err.Kind() == Unavailable || err.Kind() == TooManyRequests
+	if retryable && errors.As(err, &retryErr) && retryErr.RetryDelay() > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryErr.RetryDelay())})
+	}
+
+	return details
+}
+
+// richErrorDetail returns the protobuf representation of the given error. Only the message of the error itself is sent,
+// messages of the wrapped errors may contain details that must not reach the caller.
+func (h GRPCInterceptors) richErrorDetail(err RichError, metadata map[string]string) *richerrorpb.RichError {
+	pbErr := &richerrorpb.RichError{
+		Kind:      err.Kind().String(),
+		Level:     err.Level().String(),
+		Operation: string(err.Operation()),
+		Metadata:  metadata,
+	}
+
+	pbErr.Message = ownMessage(err)
+
+	if err.Type() != nil {
+		pbErr.Type = err.Type().String()
+	}
+
+	if h.DetailsRuntimeInfo {
+		for _, info := range err.RuntimeInfo() {
+			pbErr.RuntimeInfo = append(pbErr.RuntimeInfo, &richerrorpb.RuntimeInfo{
+				LineNumber:   int32(info.LineNumber),
+				FileName:     info.FileName,
+				FunctionName: info.FunctionName,
+			})
+		}
+	}
+
+	return pbErr
+}
+
+// detailsMetadata returns the Metadata of the error which are allowed to be sent to the caller
+func (h GRPCInterceptors) detailsMetadata(err RichError) map[string]string {
+	var metadata map[string]string
+	for _, key := range h.DetailsMetadataKeys {
+		value, ok := err.Metadata()[key]
+		if !ok {
+			continue
+		}
+
+		if metadata == nil {
+			metadata = make(map[string]string)
+		}
+		metadata[key] = fmt.Sprint(value)
+	}
+
+	return metadata
+}
+
+// ownMessage returns the message of the given error without the messages of the errors it wraps
+func ownMessage(err RichError) string {
+	if rErr, ok := err.(*richError); ok {
+		return rErr.message
+	}
+
+	return ""
+}
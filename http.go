@@ -0,0 +1,109 @@
+package richerror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// HTTPHandlerFunc is an http handler that instead of writing the error response itself returns the error, so it can
+// be logged and written by HTTPMiddleware.
+type HTTPHandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// HTTPErrorWriter writes the response of a failed http request
+type HTTPErrorWriter func(w http.ResponseWriter, r *http.Request, err error)
+
+// HTTPMiddleware is a helper that provides net/http middlewares (compatible with routers like chi) that will catch
+// and log errors of your http server. If your handlers return RichError it will set the http status code based on
+// their Kind. It doesn't depend on any web framework.
+type HTTPMiddleware struct {
+	Logger ErrorLogger
+
+	// ErrorWriter writes the response of failed requests, if not provided WriteHTTPError will be used
+	ErrorWriter HTTPErrorWriter
+
+	// ContextHeaders maps the request headers that will be stored in the request context to their field names (see
+	// ContextWithFields), if not provided DefaultContextHeaders will be used
+	ContextHeaders map[string]string
+}
+
+// GetHTTPLoggerMiddleware returns a net/http middleware that recovers panics, logs them using the given logger and
+// returns an error response with a status code that matches the Kind of the error.
+func GetHTTPLoggerMiddleware(logger ErrorLogger) func(http.Handler) http.Handler {
+	return HTTPMiddleware{Logger: logger}.Middleware
+}
+
+// Middleware wraps the given handler, in case of panic it logs the error and writes an error response using the
+// ErrorWriter.
+func (m HTTPMiddleware) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = m.seedContext(r)
+
+		defer func() {
+			if e := httpErrorFromPanic(r.URL.Path, recover()); e != nil {
+				m.handleError(w, r, e)
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// HandlerFunc adapts the given HTTPHandlerFunc to http.HandlerFunc. In case of error (or panic) it logs the error and
+// writes an error response using the ErrorWriter.
+func (m HTTPMiddleware) HandlerFunc(handler HTTPHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r = m.seedContext(r)
+
+		defer func() {
+			if e := httpErrorFromPanic(r.URL.Path, recover()); e != nil {
+				m.handleError(w, r, e)
+			}
+		}()
+
+		if err := handler(w, r); err != nil {
+			m.handleError(w, r, err)
+		}
+	}
+}
+
+func (m HTTPMiddleware) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	if m.Logger != nil {
+		LogCtx(m.Logger, r.Context(), err)
+	}
+
+	if m.ErrorWriter != nil {
+		m.ErrorWriter(w, r, err)
+		return
+	}
+
+	WriteHTTPError(w, r, err)
+}
+
+// seedContext stores the fields described by ContextHeaders, the IDs of the active span (see ContextWithSpanFields)
+// and a sentry hub scoped to the request in the request context
+func (m HTTPMiddleware) seedContext(r *http.Request) *http.Request {
+	ctx := ContextWithFields(r.Context(), fieldsFromHeaders(m.ContextHeaders, r.Header.Get))
+	ctx = ContextWithSpanFields(ctx)
+	return r.WithContext(contextWithSentryHTTPHub(ctx, r, r.RemoteAddr, ""))
+}
+
+// WriteHTTPError is the default HTTPErrorWriter, it writes the Type of the error (or its message if it has no Type)
+// as a plain text response with a status code that matches the Kind of the error.
+func WriteHTTPError(w http.ResponseWriter, _ *http.Request, err error) {
+	code, msg := getErrorStatusCodeAndMessage(err)
+	http.Error(w, msg, code)
+}
+
+func getErrorStatusCodeAndMessage(err error) (int, string) {
+	var rErr RichError
+	if errors.As(err, &rErr) {
+		message := err.Error()
+		if rErr.Type() != nil {
+			message = rErr.Type().String()
+		}
+
+		return rErr.Kind().HttpStatusCode(), message
+	}
+
+	return http.StatusInternalServerError, err.Error()
+}
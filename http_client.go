@@ -0,0 +1,157 @@
+package richerror
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodySize is the maximum number of bytes read from the body of failed http responses
+const maxErrorBodySize = 64 << 10
+
+// Assert HTTPTransport implements http.RoundTripper
+var _ http.RoundTripper = HTTPTransport{}
+
+// HTTPTransport is an http.RoundTripper that turns error responses (4xx and 5xx) and transport failures into
+// RichErrors, other responses (including redirects) are returned as is. Keep in mind that http.Client wraps errors
+// returned by transports in url.Error, so use errors.As to access the RichError.
+type HTTPTransport struct {
+	// Base is the underlying RoundTripper, if not provided http.DefaultTransport will be used
+	Base http.RoundTripper
+}
+
+func (t HTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, fromHTTPTransportError(req, err, 1)
+	}
+
+	if err := fromHTTPResponse(resp, 1); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// FromHTTPResponse returns nil unless the response is an error response (4xx or 5xx), otherwise it reads and closes
+// the response body and returns a RichError describing the failure. Kind is determined by the status code, and
+// message, Type and Metadata are filled from the body if it's a problem+json or a RichError json (see FromJSON),
+// otherwise the body is used as the message. Method and URL (without its query string) of the request are stored as
+// Metadata.
+func FromHTTPResponse(resp *http.Response) error {
+	return fromHTTPResponse(resp, 1)
+}
+
+// fromHTTPResponse converts the given error response into a RichError, skip is the number of frames to skip above its
+// caller to reach the call site that will be recorded as the runtime info of the error
+func fromHTTPResponse(resp *http.Response, skip int) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil {
+		return newRichError("failed to read error response", skip+1).
+			WithError(err).
+			WithKind(KindFromHTTPStatus(resp.StatusCode)).
+			WithFields(responseMetadata(resp))
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	var rErr *richError
+	switch {
+	case strings.EqualFold(mediaType, ProblemContentType):
+		var problem Problem
+		if json.Unmarshal(body, &problem) == nil {
+			if problem.Status == 0 {
+				problem.Status = resp.StatusCode
+			}
+			rErr = fromProblem(problem, skip+1)
+		}
+	case strings.EqualFold(mediaType, "application/json"):
+		if restored, err := FromJSON(body); err == nil {
+			rErr = restored.(*richError)
+			rErr.runtimeInfo = append([]RuntimeInfo{callerRuntimeInfo(skip + 1)}, remoteRuntimeInfo(rErr)...)
+		}
+	}
+
+	if rErr == nil {
+		message := strings.TrimSpace(string(body))
+		if message == "" {
+			message = resp.Status
+		}
+
+		rErr = newRichError(message, skip+1)
+	}
+
+	if rErr.kind == UnknownKind {
+		rErr = rErr.WithKind(KindFromHTTPStatus(resp.StatusCode))
+	}
+
+	return rErr.WithFields(responseMetadata(resp))
+}
+
+// remoteRuntimeInfo returns the runtime info restored from the json of the given error, the placeholder of errors
+// whose json had no runtime info is left out
+func remoteRuntimeInfo(r *richError) []RuntimeInfo {
+	if len(r.runtimeInfo) == 1 && r.runtimeInfo[0] == (RuntimeInfo{}) {
+		return nil
+	}
+
+	return r.runtimeInfo
+}
+
+// FromHTTPTransportError converts the error returned by an http.RoundTripper into a RichError. Timeouts have Timeout
+// Kind, canceled requests have Canceled Kind and other failures have Unavailable Kind.
+func FromHTTPTransportError(req *http.Request, err error) error {
+	return fromHTTPTransportError(req, err, 1)
+}
+
+// fromHTTPTransportError converts the given transport error into a RichError, skip is the number of frames to skip
+// above its caller to reach the call site that will be recorded as the runtime info of the error
+func fromHTTPTransportError(req *http.Request, err error, skip int) error {
+	kind := KindFromError(err)
+	if kind != Timeout && kind != Canceled {
+		kind = Unavailable
+	}
+
+	return newRichError("http request failed", skip+1).
+		WithError(err).
+		WithKind(kind).
+		WithFields(requestMetadata(req))
+}
+
+func responseMetadata(resp *http.Response) Metadata {
+	metadata := Metadata{"http_status": resp.StatusCode}
+	if resp.Request != nil {
+		for key, value := range requestMetadata(resp.Request) {
+			metadata[key] = value
+		}
+	}
+
+	return metadata
+}
+
+func requestMetadata(req *http.Request) Metadata {
+	metadata := Metadata{"http_method": req.Method}
+	if req.URL != nil {
+		// query strings may carry tokens, so they're left out like the password of the URL
+		url := *req.URL
+		url.RawQuery = ""
+		url.ForceQuery = false
+		metadata["http_url"] = url.Redacted()
+	}
+
+	return metadata
+}
@@ -1,47 +1,279 @@
 package richerror
 
-import "encoding/json"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// JSONChainMode controls the json representation of RichErrors. By default wrapped errors are written as nested
+// objects, in chain mode the whole causal chain (including branches of multi-errors) is written as an array of
+// frames under the "chain" member instead (see ErrorChain). Chain mode is meant for log pipelines, FromJSON only
+// restores the outermost error of its output.
+var JSONChainMode = false
+
+// jsonVersion is the version of the json representation of RichError. Version 1 (which had no version member) only
+// stored the first RuntimeInfo of the error.
+const jsonVersion = 2
 
 type richErrorJson struct {
-	Message      string      `json:"message,omitempty"`
-	Operation    Operation   `json:"operation,omitempty"`
-	Level        Level       `json:"level,omitempty"`
-	Kind         Kind        `json:"kind,omitempty"`
-	Type         string      `json:"type,omitempty"`
-	Fields       Metadata    `json:"fields,omitempty"`
-	RuntimeInfo  RuntimeInfo `json:"runtime_info,omitempty"`
-	WrappedError error       `json:"wrapped_error,omitempty"`
+	Version      int           `json:"version,omitempty"`
+	Message      string        `json:"message,omitempty"`
+	Operation    Operation     `json:"operation,omitempty"`
+	Level        Level         `json:"level,omitempty"`
+	Kind         Kind          `json:"kind,omitempty"`
+	Type         string        `json:"type,omitempty"`
+	Fields       Metadata      `json:"fields,omitempty"`
+	RuntimeInfo  []RuntimeInfo `json:"runtime_info,omitempty"`
+	StackTrace   []RuntimeInfo `json:"stack_trace,omitempty"`
+	WrappedError error         `json:"wrapped_error,omitempty"`
+	Chain        []ErrorFrame  `json:"chain,omitempty"`
+
+	FieldViolations []FieldViolation `json:"field_violations,omitempty"`
+	RetryDelay      string           `json:"retry_delay,omitempty"`
+}
+
+// richErrorJsonDecoder mirrors richErrorJson but keeps the members that can't be decoded directly as raw json
+type richErrorJsonDecoder struct {
+	Version      int             `json:"version"`
+	Message      string          `json:"message"`
+	Operation    Operation       `json:"operation"`
+	Level        Level           `json:"level"`
+	Kind         Kind            `json:"kind"`
+	Type         string          `json:"type"`
+	Fields       Metadata        `json:"fields"`
+	RuntimeInfo  json.RawMessage `json:"runtime_info"`
+	StackTrace   []RuntimeInfo   `json:"stack_trace"`
+	WrappedError json.RawMessage `json:"wrapped_error"`
+
+	FieldViolations []FieldViolation `json:"field_violations"`
+	RetryDelay      string           `json:"retry_delay"`
 }
 
 func (r *richError) MarshalJSON() ([]byte, error) {
 	jsonStruct := &richErrorJson{
+		Version:     jsonVersion,
 		Message:     r.message,
 		Operation:   r.operation,
 		Level:       r.level,
 		Kind:        r.kind,
 		Fields:      r.fields,
-		RuntimeInfo: r.runtimeInfo[0],
+		RuntimeInfo: r.runtimeInfo,
+		StackTrace:  r.StackTrace(),
+
+		FieldViolations: r.fieldViolations,
 	}
 
 	if r.Type() != nil {
 		jsonStruct.Type = r.Type().String()
 	}
 
+	if r.retryDelay != 0 {
+		jsonStruct.RetryDelay = r.retryDelay.String()
+	}
+
+	if JSONChainMode {
+		jsonStruct.Chain = ErrorChain(r)
+		return json.Marshal(jsonStruct)
+	}
+
 	if r.wrappedError != nil {
 		if _, ok := r.wrappedError.(json.Marshaler); ok {
 			jsonStruct.WrappedError = r.wrappedError
 		} else {
-			jsonStruct.WrappedError = &simpleError{Message: r.wrappedError.Error()}
+			jsonStruct.WrappedError = newSimpleError(r.wrappedError)
 		}
 	}
 
 	return json.Marshal(jsonStruct)
 }
 
+func (r *richError) UnmarshalJSON(data []byte) error {
+	var jsonStruct richErrorJsonDecoder
+	if err := json.Unmarshal(data, &jsonStruct); err != nil {
+		return err
+	}
+
+	if jsonStruct.Version > jsonVersion {
+		return fmt.Errorf("unsupported rich error json version %d", jsonStruct.Version)
+	}
+
+	*r = richError{
+		message:         jsonStruct.Message,
+		fields:          jsonStruct.Fields,
+		level:           jsonStruct.Level,
+		kind:            jsonStruct.Kind,
+		operation:       jsonStruct.Operation,
+		fieldViolations: jsonStruct.FieldViolations,
+	}
+
+	if r.fields == nil {
+		r.fields = make(Metadata)
+	}
+
+	if len(jsonStruct.StackTrace) != 0 {
+		r.stack = symbolizedStack(jsonStruct.StackTrace)
+	}
+
+	if jsonStruct.Type != "" {
+		r._type = StringType(jsonStruct.Type)
+	}
+
+	if jsonStruct.RetryDelay != "" {
+		delay, err := time.ParseDuration(jsonStruct.RetryDelay)
+		if err != nil {
+			return err
+		}
+		r.retryDelay = delay
+	}
+
+	if err := r.unmarshalRuntimeInfo(jsonStruct.RuntimeInfo); err != nil {
+		return err
+	}
+
+	if isJsonNull(jsonStruct.WrappedError) {
+		return nil
+	}
+
+	wrappedError, err := unmarshalWrappedError(jsonStruct.WrappedError)
+	if err != nil {
+		return err
+	}
+	r.wrappedError = wrappedError
+
+	return nil
+}
+
+// unmarshalRuntimeInfo decodes runtime info of both version 1 (single object) and version 2 (array) json
+func (r *richError) unmarshalRuntimeInfo(data json.RawMessage) error {
+	if isJsonNull(data) {
+		r.runtimeInfo = []RuntimeInfo{{}}
+		return nil
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var info RuntimeInfo
+		if err := json.Unmarshal(data, &info); err != nil {
+			return err
+		}
+
+		r.runtimeInfo = []RuntimeInfo{info}
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &r.runtimeInfo); err != nil {
+		return err
+	}
+
+	if len(r.runtimeInfo) == 0 {
+		r.runtimeInfo = []RuntimeInfo{{}}
+	}
+
+	return nil
+}
+
+// unmarshalWrappedError decodes the wrapped error, errors that don't carry runtime info were not RichErrors, so
+// they're restored as simple errors holding their message, and causes of aggregated errors are decoded one by one
+func unmarshalWrappedError(data json.RawMessage) (error, error) {
+	var probe struct {
+		Message     string            `json:"message"`
+		GoType      string            `json:"go_type"`
+		RuntimeInfo json.RawMessage   `json:"runtime_info"`
+		Errors      []json.RawMessage `json:"errors"`
+
+		WrappedError json.RawMessage `json:"wrapped_error"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+
+	if probe.Errors != nil {
+		list := &errorList{}
+		for _, data := range probe.Errors {
+			err, e := unmarshalWrappedError(data)
+			if e != nil {
+				return nil, e
+			}
+			list.errs = append(list.errs, err)
+		}
+
+		return list, nil
+	}
+
+	if isJsonNull(probe.RuntimeInfo) {
+		simple := &simpleError{Message: probe.Message, GoType: probe.GoType}
+		if !isJsonNull(probe.WrappedError) {
+			wrappedError, err := unmarshalWrappedError(probe.WrappedError)
+			if err != nil {
+				return nil, err
+			}
+			simple.WrappedError = wrappedError
+		}
+
+		return simple, nil
+	}
+
+	wrappedError := &richError{}
+	if err := json.Unmarshal(data, wrappedError); err != nil {
+		return nil, err
+	}
+
+	return wrappedError, nil
+}
+
+func isJsonNull(data json.RawMessage) bool {
+	return len(data) == 0 || bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+}
+
+// FromJSON restores a RichError from its json representation (as produced by its MarshalJSON method). Message, kind,
+// level, operation, type, fields, runtime info chain, stack trace and wrapped errors are restored. Keep in mind that
+// Type is restored as StringType, field values are restored as their json counterparts (e.g. numbers become float64),
+// kinds that are not registered in this process are restored as Unknown and wrapped errors that were not RichErrors are
+// restored as simple errors holding their message. RichErrors wrapped by other errors (e.g. using fmt.Errorf's %w) are
+// restored as well, but RichErrors wrapped by multi-errors other than Aggregate (e.g. errors.Join) are not.
+func FromJSON(data []byte) (RichError, error) {
+	r := &richError{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, err
+	}
+
+	if r.message == "" && r.wrappedError == nil {
+		return nil, errors.New("rich error json has no message")
+	}
+
+	return r, nil
+}
+
+// simpleError represents errors that are not RichErrors in json, GoType keeps the Go type of the original error.
+// WrappedError keeps the error wrapped by the original error if a RichError is wrapped somewhere below it (e.g.
+// fmt.Errorf("...: %w", richErr)), so the RichError can be restored.
 type simpleError struct {
-	Message string `json:"message"`
+	Message      string `json:"message"`
+	GoType       string `json:"go_type,omitempty"`
+	WrappedError error  `json:"wrapped_error,omitempty"`
+}
+
+func newSimpleError(err error) *simpleError {
+	simple := &simpleError{Message: err.Error(), GoType: fmt.Sprintf("%T", err)}
+
+	var rErr RichError
+	if wrappedError := errors.Unwrap(err); wrappedError != nil && errors.As(wrappedError, &rErr) {
+		if _, ok := wrappedError.(json.Marshaler); ok {
+			simple.WrappedError = wrappedError
+		} else {
+			simple.WrappedError = newSimpleError(wrappedError)
+		}
+	}
+
+	return simple
 }
 
 func (s *simpleError) Error() string {
 	return s.Message
 }
+
+func (s *simpleError) Unwrap() error {
+	return s.WrappedError
+}
@@ -1,104 +1,239 @@
 package richerror
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"os"
 
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-// Kind hints about underlying cause of error
+// Kind hints about underlying cause of error. Besides the predefined kinds, new kinds can be defined using
+// RegisterKind.
 type Kind kind
 type kind uint8
 
 const (
-	UnknownKind      Kind = iota
-	Canceled              // grpc: CANCELLED 			- http 500 Internal Server Error
-	Unknown               // grpc: UNKNOWN 				- http 500 Internal Server Error
-	InvalidArgument       // grpc: INVALID_ARGUMENT 	- http 400 Bad Request
-	Timeout               // grpc: DEADLINE_EXCEEDED 	- http 500 Internal Server Error
-	NotFound              // grpc: NOT_FOUND 			- http 404 Not Found
-	AlreadyExists         // grpc: ALREADY_EXISTS		- http 409 Conflict
-	PermissionDenied      // grpc: PERMISSION_DENIED 	- http 403 Forbidden
-	TooManyRequests       // grpc: RESOURCE_EXHAUSTED 	- http 429 Too Many Requests
-	Unimplemented         // grpc: UNIMPLEMENTED		- http 501 Not Implemented
-	Internal              // grpc: INTERNAL 			- http 500 Internal Server Error
-	Unavailable           // grpc: UNAVAILABLE 			- http 503 Service Unavailable
-	Unauthenticated       // grpc: UNAUTHENTICATED 		- http 401 Unauthorized
+	UnknownKind        Kind = iota
+	Canceled                // grpc: CANCELLED 			- http 500 Internal Server Error
+	Unknown                 // grpc: UNKNOWN 				- http 500 Internal Server Error
+	InvalidArgument         // grpc: INVALID_ARGUMENT 	- http 400 Bad Request
+	Timeout                 // grpc: DEADLINE_EXCEEDED 	- http 500 Internal Server Error
+	NotFound                // grpc: NOT_FOUND 			- http 404 Not Found
+	AlreadyExists           // grpc: ALREADY_EXISTS		- http 409 Conflict
+	PermissionDenied        // grpc: PERMISSION_DENIED 	- http 403 Forbidden
+	TooManyRequests         // grpc: RESOURCE_EXHAUSTED 	- http 429 Too Many Requests
+	Unimplemented           // grpc: UNIMPLEMENTED		- http 501 Not Implemented
+	Internal                // grpc: INTERNAL 			- http 500 Internal Server Error
+	Unavailable             // grpc: UNAVAILABLE 			- http 503 Service Unavailable
+	Unauthenticated         // grpc: UNAUTHENTICATED 		- http 401 Unauthorized
+	FailedPrecondition      // grpc: FAILED_PRECONDITION 	- http 412 Precondition Failed
+	Aborted                 // grpc: ABORTED 				- http 409 Conflict
+	OutOfRange              // grpc: OUT_OF_RANGE 		- http 416 Requested Range Not Satisfiable
+	DataLoss                // grpc: DATA_LOSS 			- http 500 Internal Server Error
 
 	Unauthorized = Unauthenticated
 	Invalid      = InvalidArgument
 	Unexpected   = Unknown
 )
 
-var kindStrings = [...]string{"_", "Canceled", "Unknown", "Invalid Argument", "Timeout", "NotFound", "Already Exists",
-	"Permission Denied", "Too Many Requests", "Unimplemented", "Internal", "Unavailable", "Unauthenticated"}
-
 func (k Kind) String() string {
-	return kindStrings[k]
+	info, ok := k.Info()
+	if !ok {
+		return fmt.Sprintf("Kind(%d)", uint8(k))
+	}
+
+	return info.Name
 }
 
 func (k Kind) MarshalJSON() ([]byte, error) {
-	return []byte(k.String()), nil
+	return json.Marshal(k.String())
 }
 
+// UnmarshalJSON decodes the kind by its name, kinds that haven't been registered in this process (e.g. custom kinds of
+// another service) are decoded as Unknown
+func (k *Kind) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+
+	kind, ok := KindByName(name)
+	if !ok {
+		kind = Unknown
+	}
+
+	*k = kind
+	return nil
+}
+
+// GRPCStatusCode returns the gRPC status code of the kind, unregistered kinds result in codes.Unknown
 func (k Kind) GRPCStatusCode() codes.Code {
-	switch k {
-	case Canceled:
-		return codes.Canceled
-	case Unknown:
-		return codes.Unknown
-	case InvalidArgument:
-		return codes.InvalidArgument
-	case Timeout:
-		return codes.DeadlineExceeded
-	case NotFound:
-		return codes.NotFound
-	case AlreadyExists:
-		return codes.AlreadyExists
-	case PermissionDenied:
-		return codes.PermissionDenied
-	case TooManyRequests:
-		return codes.ResourceExhausted
-	case Unimplemented:
-		return codes.Unimplemented
-	case Internal:
-		return codes.Internal
-	case Unavailable:
-		return codes.Unavailable
-	case Unauthenticated:
-		return codes.Unauthenticated
-	default:
+	info, ok := k.Info()
+	if !ok || k == UnknownKind {
 		return codes.Unknown
 	}
+
+	return info.GRPCStatusCode
 }
 
+// HttpStatusCode returns the http status code of the kind, unregistered kinds result in 500 Internal Server Error
 func (k Kind) HttpStatusCode() int {
-	switch k {
-	case Canceled:
-		return http.StatusInternalServerError
-	case Unknown:
-		return http.StatusInternalServerError
-	case InvalidArgument:
-		return http.StatusBadRequest
-	case Timeout:
+	info, ok := k.Info()
+	if !ok || k == UnknownKind {
 		return http.StatusInternalServerError
-	case NotFound:
-		return http.StatusNotFound
-	case AlreadyExists:
-		return http.StatusConflict
-	case PermissionDenied:
-		return http.StatusForbidden
-	case TooManyRequests:
-		return http.StatusTooManyRequests
-	case Unimplemented:
-		return http.StatusNotImplemented
-	case Internal:
-		return http.StatusInternalServerError
-	case Unavailable:
-		return http.StatusServiceUnavailable
-	case Unauthenticated:
-		return http.StatusUnauthorized
+	}
+
+	return info.HTTPStatusCode
+}
+
+// DefaultLevel returns the level of errors of this kind whose level hasn't been explicitly specified
+func (k Kind) DefaultLevel() Level {
+	info, ok := k.Info()
+	if !ok || info.DefaultLevel == UnknownLevel {
+		return Error
+	}
+
+	return info.DefaultLevel
+}
+
+// Retryable reports whether the failed operation can be retried
+func (k Kind) Retryable() bool {
+	info, _ := k.Info()
+	return info.Retryable
+}
+
+// KindFromGRPCCode returns the Kind that best describes the given gRPC status code, it's the reverse of
+// Kind.GRPCStatusCode. codes.OK results in UnknownKind. Codes that none of the predefined kinds use are looked up in
+// the registered kinds.
+func KindFromGRPCCode(code codes.Code) Kind {
+	switch code {
+	case codes.OK:
+		return UnknownKind
+	case codes.Canceled:
+		return Canceled
+	case codes.Unknown:
+		return Unknown
+	case codes.InvalidArgument:
+		return InvalidArgument
+	case codes.FailedPrecondition:
+		return FailedPrecondition
+	case codes.OutOfRange:
+		return OutOfRange
+	case codes.DeadlineExceeded:
+		return Timeout
+	case codes.NotFound:
+		return NotFound
+	case codes.AlreadyExists:
+		return AlreadyExists
+	case codes.Aborted:
+		return Aborted
+	case codes.PermissionDenied:
+		return PermissionDenied
+	case codes.ResourceExhausted:
+		return TooManyRequests
+	case codes.Unimplemented:
+		return Unimplemented
+	case codes.Internal:
+		return Internal
+	case codes.DataLoss:
+		return DataLoss
+	case codes.Unavailable:
+		return Unavailable
+	case codes.Unauthenticated:
+		return Unauthenticated
 	default:
-		return http.StatusInternalServerError
+		if kind, ok := registeredKind(func(info KindInfo) bool { return info.GRPCStatusCode == code }); ok {
+			return kind
+		}
+
+		return Unknown
+	}
+}
+
+// KindFromError returns the Kind that best describes the given error. RichErrors report their own Kind, and
+// context.Canceled, context.DeadlineExceeded, os.ErrNotExist, os.ErrExist, os.ErrPermission, sql.ErrNoRows, net
+// timeout errors and gRPC status errors are recognised (even if they're wrapped). Other errors result in Unknown and
+// nil results in UnknownKind.
+func KindFromError(err error) Kind {
+	if err == nil {
+		return UnknownKind
+	}
+
+	var rErr RichError
+	if errors.As(err, &rErr) && rErr.Kind() != Unknown {
+		return rErr.Kind()
+	}
+
+	var netErr net.Error
+	var grpcErr interface{ GRPCStatus() *status.Status }
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	case errors.Is(err, os.ErrNotExist), errors.Is(err, sql.ErrNoRows):
+		return NotFound
+	case errors.Is(err, os.ErrExist):
+		return AlreadyExists
+	case errors.Is(err, os.ErrPermission):
+		return PermissionDenied
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return Timeout
+	case errors.As(err, &grpcErr):
+		if kind := KindFromGRPCCode(grpcErr.GRPCStatus().Code()); kind != UnknownKind {
+			return kind
+		}
+	}
+
+	return Unknown
+}
+
+// KindFromHTTPStatus returns the Kind that best describes the given http status code, it's the reverse of
+// Kind.HttpStatusCode. Status codes below 400 result in UnknownKind. Status codes that none of the predefined kinds use
+// are looked up in the registered kinds.
+func KindFromHTTPStatus(code int) Kind {
+	switch {
+	case code < http.StatusBadRequest:
+		return UnknownKind
+	case code == http.StatusBadRequest, code == http.StatusUnprocessableEntity, code == http.StatusRequestEntityTooLarge:
+		return InvalidArgument
+	case code == http.StatusPreconditionFailed:
+		return FailedPrecondition
+	case code == http.StatusRequestedRangeNotSatisfiable:
+		return OutOfRange
+	case code == http.StatusUnauthorized:
+		return Unauthenticated
+	case code == http.StatusForbidden:
+		return PermissionDenied
+	case code == http.StatusNotFound, code == http.StatusGone:
+		return NotFound
+	case code == http.StatusConflict:
+		return AlreadyExists
+	case code == http.StatusRequestTimeout, code == http.StatusGatewayTimeout:
+		return Timeout
+	case code == http.StatusTooManyRequests:
+		return TooManyRequests
+	case code == 499: // client closed request (nginx)
+		return Canceled
+	case code == http.StatusNotImplemented, code == http.StatusMethodNotAllowed:
+		return Unimplemented
+	case code == http.StatusServiceUnavailable, code == http.StatusBadGateway:
+		return Unavailable
+	case code == http.StatusInternalServerError:
+		return Internal
+	default:
+		if kind, ok := registeredKind(func(info KindInfo) bool { return info.HTTPStatusCode == code }); ok {
+			return kind
+		}
+
+		return Unknown
 	}
 }
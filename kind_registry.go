@@ -0,0 +1,125 @@
+package richerror
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+)
+
+// KindInfo describes a Kind
+type KindInfo struct {
+	// Name is the string representation of the kind, it has to be unique
+	Name string
+
+	GRPCStatusCode codes.Code
+	HTTPStatusCode int
+
+	// DefaultLevel is the level of errors of this kind whose level hasn't been explicitly specified, if not provided
+	// Error will be used
+	DefaultLevel Level
+
+	// Retryable reports whether operations failed with this kind can be retried
+	Retryable bool
+}
+
+var (
+	kindsMu sync.RWMutex
+	kinds   = []KindInfo{
+		UnknownKind:        {Name: "_", GRPCStatusCode: codes.Unknown, HTTPStatusCode: http.StatusInternalServerError},
+		Canceled:           {Name: "Canceled", GRPCStatusCode: codes.Canceled, HTTPStatusCode: http.StatusInternalServerError},
+		Unknown:            {Name: "Unknown", GRPCStatusCode: codes.Unknown, HTTPStatusCode: http.StatusInternalServerError},
+		InvalidArgument:    {Name: "Invalid Argument", GRPCStatusCode: codes.InvalidArgument, HTTPStatusCode: http.StatusBadRequest},
+		Timeout:            {Name: "Timeout", GRPCStatusCode: codes.DeadlineExceeded, HTTPStatusCode: http.StatusInternalServerError, Retryable: true},
+		NotFound:           {Name: "NotFound", GRPCStatusCode: codes.NotFound, HTTPStatusCode: http.StatusNotFound},
+		AlreadyExists:      {Name: "Already Exists", GRPCStatusCode: codes.AlreadyExists, HTTPStatusCode: http.StatusConflict},
+		PermissionDenied:   {Name: "Permission Denied", GRPCStatusCode: codes.PermissionDenied, HTTPStatusCode: http.StatusForbidden},
+		TooManyRequests:    {Name: "Too Many Requests", GRPCStatusCode: codes.ResourceExhausted, HTTPStatusCode: http.StatusTooManyRequests, Retryable: true},
+		Unimplemented:      {Name: "Unimplemented", GRPCStatusCode: codes.Unimplemented, HTTPStatusCode: http.StatusNotImplemented},
+		Internal:           {Name: "Internal", GRPCStatusCode: codes.Internal, HTTPStatusCode: http.StatusInternalServerError},
+		Unavailable:        {Name: "Unavailable", GRPCStatusCode: codes.Unavailable, HTTPStatusCode: http.StatusServiceUnavailable, Retryable: true},
+		Unauthenticated:    {Name: "Unauthenticated", GRPCStatusCode: codes.Unauthenticated, HTTPStatusCode: http.StatusUnauthorized},
+		FailedPrecondition: {Name: "Failed Precondition", GRPCStatusCode: codes.FailedPrecondition, HTTPStatusCode: http.StatusPreconditionFailed},
+		Aborted:            {Name: "Aborted", GRPCStatusCode: codes.Aborted, HTTPStatusCode: http.StatusConflict, Retryable: true},
+		OutOfRange:         {Name: "Out Of Range", GRPCStatusCode: codes.OutOfRange, HTTPStatusCode: http.StatusRequestedRangeNotSatisfiable},
+		DataLoss:           {Name: "Data Loss", GRPCStatusCode: codes.DataLoss, HTTPStatusCode: http.StatusInternalServerError},
+	}
+
+	// predefinedKinds is the number of kinds defined by this package
+	predefinedKinds = len(kinds)
+)
+
+// RegisterKind registers a new Kind described by the given info and returns it. Kinds should be registered during
+// program initialization, e.g.
+//
+//	var PaymentRequired = richerror.RegisterKind(richerror.KindInfo{
+//		Name:           "Payment Required",
+//		GRPCStatusCode: codes.FailedPrecondition,
+//		HTTPStatusCode: http.StatusPaymentRequired,
+//		DefaultLevel:   richerror.Warning,
+//	})
+//
+// It panics if the name is empty or already registered, or if there is no room for a new kind.
+func RegisterKind(info KindInfo) Kind {
+	kindsMu.Lock()
+	defer kindsMu.Unlock()
+
+	if info.Name == "" {
+		panic("richerror: RegisterKind called with an empty name")
+	}
+
+	for _, registered := range kinds {
+		if registered.Name == info.Name {
+			panic(fmt.Sprintf("richerror: RegisterKind called twice for kind %q", info.Name))
+		}
+	}
+
+	if len(kinds) > math.MaxUint8 {
+		panic(fmt.Sprintf("richerror: too many kinds registered, can't register %q", info.Name))
+	}
+
+	kinds = append(kinds, info)
+	return Kind(len(kinds) - 1)
+}
+
+// KindByName returns the Kind whose string representation is the given name
+func KindByName(name string) (Kind, bool) {
+	kindsMu.RLock()
+	defer kindsMu.RUnlock()
+
+	for i, info := range kinds {
+		if info.Name == name {
+			return Kind(i), true
+		}
+	}
+
+	return UnknownKind, false
+}
+
+// Info returns the description of the kind, it returns false if the kind hasn't been registered
+func (k Kind) Info() (KindInfo, bool) {
+	kindsMu.RLock()
+	defer kindsMu.RUnlock()
+
+	if int(k) >= len(kinds) {
+		return KindInfo{}, false
+	}
+
+	return kinds[k], true
+}
+
+// registeredKind returns the first kind registered using RegisterKind that matches the given predicate
+func registeredKind(match func(KindInfo) bool) (Kind, bool) {
+	kindsMu.RLock()
+	defer kindsMu.RUnlock()
+
+	for i := predefinedKinds; i < len(kinds); i++ {
+		if match(kinds[i]) {
+			return Kind(i), true
+		}
+	}
+
+	return UnknownKind, false
+}
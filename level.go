@@ -1,6 +1,11 @@
 package richerror
 
-import "github.com/getsentry/sentry-go"
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/getsentry/sentry-go"
+)
 
 // Level identifies severity of the error
 type Level level
@@ -17,11 +22,30 @@ const (
 var levelStrings = [...]string{"_", "Fatal", "Error", "Warning", "Info"}
 
 func (l Level) String() string {
+	if int(l) >= len(levelStrings) {
+		return fmt.Sprintf("Level(%d)", uint8(l))
+	}
+
 	return levelStrings[l]
 }
 
 func (l Level) MarshalJSON() ([]byte, error) {
-	return []byte(l.String()), nil
+	return json.Marshal(l.String())
+}
+
+func (l *Level) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+
+	level := levelFromString(name)
+	if level == UnknownLevel && name != UnknownLevel.String() {
+		return fmt.Errorf("unknown level %q", name)
+	}
+
+	*l = level
+	return nil
 }
 
 func (l Level) SentryLevel() sentry.Level {
@@ -38,3 +62,14 @@ func (l Level) SentryLevel() sentry.Level {
 		return sentry.LevelError
 	}
 }
+
+// levelFromString returns the Level whose string representation is the given string
+func levelFromString(s string) Level {
+	for i, str := range levelStrings {
+		if str == s {
+			return Level(i)
+		}
+	}
+
+	return UnknownLevel
+}
@@ -0,0 +1,100 @@
+package richerror
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// logField is a single field of the structured representation of an error used by the structured logging adapters
+// (ZapLogger, ZerologLogger and SlogLogger)
+type logField struct {
+	key   string
+	value interface{}
+}
+
+// reservedLogKeys are the keys used by the structured representation of errors, metadata can't override them
+var reservedLogKeys = map[string]bool{
+	"message": true, "kind": true, "level": true, "operation": true, "type": true, "runtime_info": true,
+	"stack_trace": true, "cause": true, "causes": true, "go_type": true, "error": true,
+}
+
+// layerLogFields returns the fields describing the layer of the error itself, without its causes. The metadata is only
+// written once: WithError merges the metadata of the wrapped error into the error, so wrapped errors leave it out
+// (causes of aggregated errors keep theirs), and the structured logging adapters write it as top level fields instead.
+func (r *richError) layerLogFields(withMetadata bool) []logField {
+	fields := []logField{
+		{key: "message", value: r.message},
+		{key: "kind", value: r.Kind().String()},
+		{key: "level", value: r.Level().String()},
+	}
+
+	if r.operation != "" {
+		fields = append(fields, logField{key: "operation", value: string(r.operation)})
+	}
+
+	if r._type != nil {
+		fields = append(fields, logField{key: "type", value: r._type.String()})
+	}
+
+	if withMetadata {
+		fields = append(fields, metadataLogFields(r.fields)...)
+	}
+
+	if len(r.runtimeInfo) != 0 {
+		fields = append(fields, logField{key: "runtime_info", value: r.runtimeInfo[0].String()})
+	}
+
+	if stackTrace := r.StackTrace(); len(stackTrace) != 0 {
+		frames := make([]string, 0, len(stackTrace))
+		for _, frame := range stackTrace {
+			frames = append(frames, fmt.Sprintf("%s %s:%d", frame.FunctionName, frame.FileName, frame.LineNumber))
+		}
+		fields = append(fields, logField{key: "stack_trace", value: frames})
+	}
+
+	return fields
+}
+
+// metadataLogFields returns the given metadata as fields sorted by their keys, keeping the types of their values
+func metadataLogFields(metadata Metadata) []logField {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		if !reservedLogKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	fields := make([]logField, 0, len(keys))
+	for _, key := range keys {
+		fields = append(fields, logField{key: key, value: metadata[key]})
+	}
+
+	return fields
+}
+
+// errorLogFields returns the metadata of the error (merged with the context fields) that the structured logging
+// adapters write as top level fields, along with the level of the error
+func errorLogFields(err error, contextFields Metadata) ([]logField, Level) {
+	var rErr RichError
+	if !errors.As(err, &rErr) {
+		return metadataLogFields(contextFields), Error
+	}
+
+	return metadataLogFields(mergeFields(rErr.Metadata(), contextFields)), rErr.Level()
+}
+
+// metadataFromKeyValues converts the key value pairs given to LogInfoWithMetadata to Metadata
+func metadataFromKeyValues(keyValues []interface{}) Metadata {
+	metadata := make(Metadata, len(keyValues)/2)
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		key, ok := keyValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyValues[i])
+		}
+		metadata[key] = keyValues[i+1]
+	}
+
+	return metadata
+}
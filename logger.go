@@ -1,12 +1,14 @@
 package richerror
 
 import (
+	"context"
 	"errors"
 	"fmt"
 )
 
-// Assert Logger implements ErrorLogger
-var _ ErrorLogger = ChainLogger{}
+// Assert ChainLogger implements ContextErrorLogger and ContextInfoLogger
+var _ ContextErrorLogger = ChainLogger{}
+var _ ContextInfoLogger = ChainLogger{}
 
 // ChainLogger is an ErrorLogger that upon being called will call a set of loggers one after the other
 type ChainLogger struct {
@@ -31,12 +33,24 @@ func (c ChainLogger) LogInfoWithMetadata(msg string, metadata ...interface{}) {
 	}
 }
 
+func (c ChainLogger) LogCtx(ctx context.Context, err error) {
+	for _, logger := range c.Loggers {
+		LogCtx(logger, ctx, err)
+	}
+}
+
+func (c ChainLogger) LogInfoCtx(ctx context.Context, msg string, metadata ...interface{}) {
+	for _, logger := range c.Loggers {
+		LogInfoCtx(logger, ctx, msg, metadata...)
+	}
+}
+
 func ChainLoggers(loggers ...ErrorLogger) ErrorLogger {
 	return ChainLogger{Loggers: loggers}
 }
 
-// Assert Logger implements ErrorLogger
-var _ ErrorLogger = Logger{}
+// Assert Logger implements ContextErrorLogger
+var _ ContextErrorLogger = Logger{}
 
 // Logger is a struct that provides an ErrorLogger. The resulting ErrorLogger will log RichErrors given to it as
 // descriptive as it can (based on the loggers abilities). Keep in mind that it's the module users' responsibility to
@@ -51,18 +65,25 @@ type Logger struct {
 }
 
 func (l Logger) Log(err error) {
+	l.LogCtx(context.Background(), err)
+}
+
+// LogCtx logs the error along with the fields stored in ctx (see ContextWithFields). Fields are only logged by
+// ContextLogger, FormattedLogger, BasicLogger, GoLogger and the fmt.Println fallback ignore them (fields added to
+// RichErrors using NewCtx or WithContext are part of their Metadata, so they're logged anyway).
+func (l Logger) LogCtx(ctx context.Context, err error) {
 	var rErr RichError
 	ok := errors.As(err, &rErr)
 	if !ok {
-		l.logNormalError(err)
+		l.logNormalError(err, FieldsFromContext(ctx))
 		return
 	}
 
-	l.logRichError(rErr)
+	l.logRichError(rErr, FieldsFromContext(ctx))
 }
 
 func (l Logger) LogInfo(msg string) {
-	l.logRichError(New(msg).WithLevel(Info))
+	l.logRichError(New(msg).WithLevel(Info), nil)
 }
 
 func (l Logger) LogInfoWithMetadata(msg string, metadata ...interface{}) {
@@ -77,7 +98,7 @@ func (l Logger) LogInfoWithMetadata(msg string, metadata ...interface{}) {
 		err = err.WithField(key, datum)
 		key = ""
 	}
-	l.logRichError(err)
+	l.logRichError(err, nil)
 }
 
 type GoLogger interface {
@@ -113,8 +134,13 @@ type ContextLogger interface {
 	Fatalw(string, ...interface{})
 }
 
-func (l Logger) logNormalError(err error) {
+func (l Logger) logNormalError(err error, contextFields Metadata) {
 	if l.ContextLogger != nil {
+		if len(contextFields) != 0 {
+			l.ContextLogger.Errorw(err.Error(), "metadata", contextFields)
+			return
+		}
+
 		l.ContextLogger.Errorw(err.Error())
 		return
 	}
@@ -137,14 +163,19 @@ func (l Logger) logNormalError(err error) {
 	fmt.Printf("error: %s\n", err.Error())
 }
 
-func (l Logger) logRichError(err RichError) {
+func (l Logger) logRichError(err RichError, contextFields Metadata) {
 	if l.ContextLogger != nil {
 		contexts := []interface{}{
-			"metadata", err.Metadata(),
+			"metadata", mergeFields(err.Metadata(), contextFields),
 		}
 
 		if err.Level() != Info {
 			contexts = append(contexts, "runtime_info", err.RuntimeInfo())
+
+			var stackErr StackTraceError
+			if errors.As(err, &stackErr) && len(stackErr.StackTrace()) != 0 {
+				contexts = append(contexts, "stack_trace", stackErr.StackTrace())
+			}
 		}
 
 		switch err.Level() {
@@ -0,0 +1,141 @@
+package richerror
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// otherLabel is the label value that replaces the values that exceed the cardinality limits
+const otherLabel = "other"
+
+// defaultMaxLabelValues is the default number of distinct values of the operation and type labels
+const defaultMaxLabelValues = 100
+
+// ErrorLabels are the labels of an error counter
+type ErrorLabels struct {
+	Kind      string
+	Level     string
+	Operation string
+	Type      string
+	// Route is the gRPC method or Echo route that failed, it's empty for errors logged outside of the interceptors
+	Route string
+}
+
+// ErrorCounter counts errors, see PrometheusErrorCounter and OpenTelemetryErrorCounter
+type ErrorCounter interface {
+	Inc(ctx context.Context, labels ErrorLabels)
+}
+
+// Assert MetricsLogger implements ContextErrorLogger
+var _ ContextErrorLogger = &MetricsLogger{}
+
+// MetricsLogger is an ErrorLogger that counts errors labeled by their kind, level, operation and type (and the gRPC
+// method or Echo route if they're logged by the interceptors). To keep the cardinality of the metrics under control,
+// operations and types can be limited to allow-lists, otherwise only the first MaxOperations (MaxTypes) distinct values
+// are kept, in both cases other values are reported as "other".
+type MetricsLogger struct {
+	Counter ErrorCounter
+
+	// AllowedOperations is the allow-list of operations, if empty the first MaxOperations operations are allowed
+	AllowedOperations []string
+	// MaxOperations is the number of distinct operations if there is no allow-list, defaults to 100
+	MaxOperations int
+
+	// AllowedTypes is the allow-list of types, if empty the first MaxTypes types are allowed
+	AllowedTypes []string
+	// MaxTypes is the number of distinct types if there is no allow-list, defaults to 100
+	MaxTypes int
+
+	operations labelLimiter
+	types      labelLimiter
+}
+
+func (m *MetricsLogger) Log(err error) {
+	m.LogCtx(context.Background(), err)
+}
+
+func (m *MetricsLogger) LogCtx(ctx context.Context, err error) {
+	labels := ErrorLabels{
+		Kind:  Unknown.String(),
+		Level: Error.String(),
+		Route: routeFromContext(ctx),
+	}
+
+	var rErr RichError
+	if errors.As(err, &rErr) {
+		labels.Kind = rErr.Kind().String()
+		labels.Level = rErr.Level().String()
+		labels.Operation = m.operations.label(string(rErr.Operation()), m.AllowedOperations, m.MaxOperations)
+		if rErr.Type() != nil {
+			labels.Type = m.types.label(rErr.Type().String(), m.AllowedTypes, m.MaxTypes)
+		}
+	}
+
+	m.Counter.Inc(ctx, labels)
+}
+
+func (m *MetricsLogger) LogInfo(string) {}
+
+func (m *MetricsLogger) LogInfoWithMetadata(string, ...interface{}) {}
+
+// labelLimiter limits the number of distinct values of a label
+type labelLimiter struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+// label returns the value if it's allowed, otherwise it returns "other". Empty values are always allowed.
+func (l *labelLimiter) label(value string, allowList []string, max int) string {
+	if value == "" {
+		return ""
+	}
+
+	if len(allowList) != 0 {
+		for _, allowed := range allowList {
+			if allowed == value {
+				return value
+			}
+		}
+
+		return otherLabel
+	}
+
+	if max <= 0 {
+		max = defaultMaxLabelValues
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.seen == nil {
+		l.seen = make(map[string]struct{})
+	}
+
+	if _, ok := l.seen[value]; ok {
+		return value
+	}
+
+	if len(l.seen) >= max {
+		return otherLabel
+	}
+
+	l.seen[value] = struct{}{}
+	return value
+}
+
+type contextRouteKey struct{}
+
+// contextWithRoute stores the gRPC method or Echo route of the request in ctx
+func contextWithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, contextRouteKey{}, route)
+}
+
+func routeFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	route, _ := ctx.Value(contextRouteKey{}).(string)
+	return route
+}
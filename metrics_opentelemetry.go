@@ -0,0 +1,38 @@
+package richerror
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Assert OpenTelemetryErrorCounter implements ErrorCounter
+var _ ErrorCounter = &OpenTelemetryErrorCounter{}
+
+// OpenTelemetryErrorCounter is an ErrorCounter that counts errors using an OpenTelemetry counter named
+// richerror.errors with kind, level, operation, type and route attributes
+type OpenTelemetryErrorCounter struct {
+	counter metric.Int64Counter
+}
+
+// NewOpenTelemetryErrorCounter creates a new OpenTelemetryErrorCounter using the given meter
+func NewOpenTelemetryErrorCounter(meter metric.Meter) (*OpenTelemetryErrorCounter, error) {
+	counter, err := meter.Int64Counter("richerror.errors",
+		metric.WithDescription("Number of errors labeled by their kind, level, operation, type and route."))
+	if err != nil {
+		return nil, err
+	}
+
+	return &OpenTelemetryErrorCounter{counter: counter}, nil
+}
+
+func (o *OpenTelemetryErrorCounter) Inc(ctx context.Context, labels ErrorLabels) {
+	o.counter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("kind", labels.Kind),
+		attribute.String("level", labels.Level),
+		attribute.String("operation", labels.Operation),
+		attribute.String("type", labels.Type),
+		attribute.String("route", labels.Route),
+	))
+}
@@ -0,0 +1,40 @@
+package richerror
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Assert PrometheusErrorCounter implements ErrorCounter
+var _ ErrorCounter = &PrometheusErrorCounter{}
+
+// PrometheusErrorCounter is an ErrorCounter that counts errors using a Prometheus counter named
+// <namespace>_errors_total labeled by kind, level, operation, type and route
+type PrometheusErrorCounter struct {
+	counter *prometheus.CounterVec
+}
+
+// NewPrometheusErrorCounter creates a new PrometheusErrorCounter and registers its counter using the given registerer
+// (prometheus.DefaultRegisterer if nil)
+func NewPrometheusErrorCounter(registerer prometheus.Registerer, namespace string) (*PrometheusErrorCounter, error) {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "errors_total",
+		Help:      "Number of errors labeled by their kind, level, operation, type and route.",
+	}, []string{"kind", "level", "operation", "type", "route"})
+
+	if err := registerer.Register(counter); err != nil {
+		return nil, err
+	}
+
+	return &PrometheusErrorCounter{counter: counter}, nil
+}
+
+func (p *PrometheusErrorCounter) Inc(_ context.Context, labels ErrorLabels) {
+	p.counter.WithLabelValues(labels.Kind, labels.Level, labels.Operation, labels.Type, labels.Route).Inc()
+}
@@ -0,0 +1,127 @@
+package richerror
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// errorList holds the causes of an aggregated error, it follows go1.20 multi-error conventions (Unwrap() []error) so
+// errors.Is and errors.As check every one of its branches
+type errorList struct {
+	errs []error
+}
+
+// Aggregate creates a new RichError that aggregates the given errors (nil errors are discarded). If the aggregated
+// error has no explicit level, the worst level of its causes will be used, and if it has no explicit kind, the most
+// common kind of its causes will be used (ties are broken by the order of causes). Use NilIfNoError to return nil when
+// there was no error to aggregate.
+func Aggregate(message string, errs ...error) *richError {
+	return newRichError(message, 1).WithErrors(errs...)
+}
+
+// WithErrors appends given errors (nil errors are discarded) to the causes of the error, turning it to an aggregated
+// error (see Aggregate). If the error already wraps an error, it becomes one of the causes.
+func (r *richError) WithErrors(errs ...error) *richError {
+	r = r.mutable()
+
+	var list *errorList
+	switch wrappedError := r.wrappedError.(type) {
+	case *errorList:
+		list = &errorList{errs: append([]error(nil), wrappedError.errs...)}
+	case nil:
+		list = &errorList{}
+	default:
+		list = &errorList{errs: []error{wrappedError}}
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			list.errs = append(list.errs, err)
+		}
+	}
+
+	if len(list.errs) != 0 {
+		r.wrappedError = list
+	}
+
+	return r
+}
+
+// Errors returns the causes of an aggregated error, it returns nil if the error is not an aggregated error
+func (r *richError) Errors() []error {
+	list, ok := r.wrappedError.(*errorList)
+	if !ok {
+		return nil
+	}
+
+	return list.errs
+}
+
+func (l *errorList) Error() string {
+	messages := make([]string, 0, len(l.errs))
+	for _, err := range l.errs {
+		messages = append(messages, err.Error())
+	}
+
+	return "[" + strings.Join(messages, "; ") + "]"
+}
+
+func (l *errorList) Unwrap() []error {
+	return l.errs
+}
+
+// level returns the worst level of the errors
+func (l *errorList) level() Level {
+	worst := UnknownLevel
+	for _, err := range l.errs {
+		level := Error
+
+		var rErr RichError
+		if errors.As(err, &rErr) {
+			level = rErr.Level()
+		}
+
+		if worst == UnknownLevel || (level != UnknownLevel && level < worst) {
+			worst = level
+		}
+	}
+
+	return worst
+}
+
+// kind returns the most common kind of the errors
+func (l *errorList) kind() Kind {
+	counts := make(map[Kind]int)
+	dominant := UnknownKind
+	for _, err := range l.errs {
+		kind := KindFromError(err)
+		if kind == Unknown {
+			continue
+		}
+
+		counts[kind]++
+		if dominant == UnknownKind || counts[kind] > counts[dominant] {
+			dominant = kind
+		}
+	}
+
+	return dominant
+}
+
+func (l *errorList) MarshalJSON() ([]byte, error) {
+	jsonStruct := struct {
+		Message string        `json:"message"`
+		Errors  []interface{} `json:"errors"`
+	}{Message: l.Error()}
+
+	for _, err := range l.errs {
+		if _, ok := err.(json.Marshaler); ok {
+			jsonStruct.Errors = append(jsonStruct.Errors, err)
+		} else {
+			jsonStruct.Errors = append(jsonStruct.Errors, newSimpleError(err))
+		}
+	}
+
+	return json.Marshal(jsonStruct)
+}
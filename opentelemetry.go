@@ -0,0 +1,118 @@
+package richerror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Assert OpenTelemetryLogger implements ContextErrorLogger
+var _ ContextErrorLogger = OpenTelemetryLogger{}
+
+// OpenTelemetryLogger is an ErrorLogger that records errors on the active OpenTelemetry span of the context given to
+// LogCtx (errors logged without context are ignored). Errors are recorded along with their Kind, Level, Operation,
+// Type and Metadata as attributes, and the span status is set to Error for errors whose level is Error or Fatal.
+//
+// It never modifies the errors it records, use ContextWithSpanFields to let other loggers link the errors to their
+// trace.
+type OpenTelemetryLogger struct {
+	// AttributePrefix is prepended to the attribute keys, if not provided "richerror." will be used
+	AttributePrefix string
+}
+
+func (o OpenTelemetryLogger) Log(error) {}
+
+func (o OpenTelemetryLogger) LogCtx(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	var rErr RichError
+	if !errors.As(err, &rErr) {
+		span.RecordError(err, trace.WithAttributes(o.fieldAttributes(FieldsFromContext(ctx))...))
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+
+	span.RecordError(err, trace.WithAttributes(o.attributes(rErr, FieldsFromContext(ctx))...))
+	if rErr.Level() == Error || rErr.Level() == Fatal {
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// ContextWithSpanFields returns a copy of ctx that carries the trace and span IDs of its active span as the trace_id
+// and span_id fields (see ContextWithFields), so errors logged with the returned context can be linked to their trace
+// by every ContextErrorLogger. ctx is returned as is if it has no valid span.
+func ContextWithSpanFields(ctx context.Context) context.Context {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return ctx
+	}
+
+	return ContextWithFields(ctx, Metadata{
+		"trace_id": spanContext.TraceID().String(),
+		"span_id":  spanContext.SpanID().String(),
+	})
+}
+
+func (o OpenTelemetryLogger) LogInfo(string) {}
+
+func (o OpenTelemetryLogger) LogInfoWithMetadata(string, ...interface{}) {}
+
+func (o OpenTelemetryLogger) attributes(err RichError, contextFields Metadata) []attribute.KeyValue {
+	prefix := o.prefix()
+	attributes := []attribute.KeyValue{
+		attribute.String(prefix+"kind", err.Kind().String()),
+		attribute.String(prefix+"level", err.Level().String()),
+	}
+
+	if err.Operation() != "" {
+		attributes = append(attributes, attribute.String(prefix+"operation", string(err.Operation())))
+	}
+
+	if err.Type() != nil {
+		attributes = append(attributes, attribute.String(prefix+"type", err.Type().String()))
+	}
+
+	return append(attributes, o.fieldAttributes(mergeFields(err.Metadata(), contextFields))...)
+}
+
+// fieldAttributes converts the given fields to attributes keeping their types if possible
+func (o OpenTelemetryLogger) fieldAttributes(fields Metadata) []attribute.KeyValue {
+	prefix := o.prefix() + "metadata."
+
+	attributes := make([]attribute.KeyValue, 0, len(fields))
+	for key, value := range fields {
+		switch value := value.(type) {
+		case string:
+			attributes = append(attributes, attribute.String(prefix+key, value))
+		case bool:
+			attributes = append(attributes, attribute.Bool(prefix+key, value))
+		case int:
+			attributes = append(attributes, attribute.Int(prefix+key, value))
+		case int64:
+			attributes = append(attributes, attribute.Int64(prefix+key, value))
+		case float64:
+			attributes = append(attributes, attribute.Float64(prefix+key, value))
+		case fmt.Stringer:
+			attributes = append(attributes, attribute.Stringer(prefix+key, value))
+		default:
+			attributes = append(attributes, attribute.String(prefix+key, fmt.Sprint(value)))
+		}
+	}
+
+	return attributes
+}
+
+func (o OpenTelemetryLogger) prefix() string {
+	if o.AttributePrefix == "" {
+		return "richerror."
+	}
+
+	return o.AttributePrefix
+}
@@ -0,0 +1,114 @@
+package richerror
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestTracer() (*tracetest.InMemoryExporter, *sdktrace.TracerProvider) {
+	exporter := tracetest.NewInMemoryExporter()
+	return exporter, sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+}
+
+func TestOpenTelemetryLoggerRecordsErrorOnSpan(t *testing.T) {
+	exporter, provider := newTestTracer()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "operation")
+
+	err := New("not found").WithKind(NotFound).WithLevel(Error).WithOperation("get_user").WithField("user_id", 42)
+	OpenTelemetryLogger{}.LogCtx(ctx, err)
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	if spans[0].Status.Code != codes.Error {
+		t.Errorf("expected span status Error, got %v", spans[0].Status.Code)
+	}
+
+	if len(spans[0].Events) != 1 || spans[0].Events[0].Name != "exception" {
+		t.Fatalf("expected a single exception event, got %v", spans[0].Events)
+	}
+
+	attributes := attribute.NewSet(spans[0].Events[0].Attributes...)
+	expected := map[attribute.Key]attribute.Value{
+		"richerror.kind":             attribute.StringValue("NotFound"),
+		"richerror.level":            attribute.StringValue("Error"),
+		"richerror.operation":        attribute.StringValue("get_user"),
+		"richerror.metadata.user_id": attribute.IntValue(42),
+	}
+	for key, value := range expected {
+		if actual, ok := attributes.Value(key); !ok || actual != value {
+			t.Errorf("expected attribute %s=%v, got %v", key, value.Emit(), actual.Emit())
+		}
+	}
+}
+
+func TestOpenTelemetryLoggerDoesNotModifyError(t *testing.T) {
+	_, provider := newTestTracer()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "operation")
+	defer span.End()
+
+	err := New("shared").WithField("key", "value")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			OpenTelemetryLogger{}.LogCtx(ctx, err)
+		}()
+	}
+	wg.Wait()
+
+	if len(err.Metadata()) != 1 {
+		t.Errorf("expected the metadata of the error to be left as is, got %v", err.Metadata())
+	}
+}
+
+func TestContextWithSpanFields(t *testing.T) {
+	_, provider := newTestTracer()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "operation")
+	defer span.End()
+
+	fields := FieldsFromContext(ContextWithSpanFields(ctx))
+	if fields["trace_id"] != span.SpanContext().TraceID().String() {
+		t.Errorf("expected trace_id %s, got %v", span.SpanContext().TraceID(), fields["trace_id"])
+	}
+	if fields["span_id"] != span.SpanContext().SpanID().String() {
+		t.Errorf("expected span_id %s, got %v", span.SpanContext().SpanID(), fields["span_id"])
+	}
+
+	if ctx := context.Background(); ContextWithSpanFields(ctx) != ctx {
+		t.Error("expected a context without span to be returned as is")
+	}
+}
+
+func TestHTTPMiddlewareAddsSpanFieldsToErrors(t *testing.T) {
+	_, provider := newTestTracer()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "request")
+	defer span.End()
+
+	var err RichError
+	handler := HTTPMiddleware{}.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		err = New("failed").WithContext(r.Context())
+		return err
+	})
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+
+	if err.Metadata()["trace_id"] != span.SpanContext().TraceID().String() {
+		t.Errorf("expected trace_id %s, got %v", span.SpanContext().TraceID(), err.Metadata()["trace_id"])
+	}
+	if err.Metadata()["span_id"] != span.SpanContext().SpanID().String() {
+		t.Errorf("expected span_id %s, got %v", span.SpanContext().SpanID(), err.Metadata()["span_id"])
+	}
+}
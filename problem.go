@@ -0,0 +1,230 @@
+package richerror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ProblemContentType is the media type of RFC 7807 (RFC 9457) problem details
+const ProblemContentType = "application/problem+json"
+
+// problemMembers are the members of Problem that can't be used as extension members
+var problemMembers = map[string]bool{
+	"type": true, "title": true, "status": true, "detail": true, "instance": true, "invalid-params": true,
+}
+
+// Problem is an RFC 7807 (RFC 9457) problem details object. Extensions hold the extension members of the problem
+// and are marshalled as top level members.
+type Problem struct {
+	Type          string           `json:"type,omitempty"`
+	Title         string           `json:"title,omitempty"`
+	Status        int              `json:"status,omitempty"`
+	Detail        string           `json:"detail,omitempty"`
+	Instance      string           `json:"instance,omitempty"`
+	InvalidParams []FieldViolation `json:"invalid-params,omitempty"`
+
+	Extensions Metadata `json:"-"`
+}
+
+type problemJson Problem
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions))
+	for key, value := range p.Extensions {
+		if !problemMembers[key] {
+			members[key] = value
+		}
+	}
+
+	data, err := json.Marshal(problemJson(p))
+	if err != nil || len(members) == 0 {
+		return data, err
+	}
+
+	extensions, err := json.Marshal(members)
+	if err != nil {
+		return nil, err
+	}
+
+	if string(data) == "{}" {
+		return extensions, nil
+	}
+
+	// merge the two objects: `{"type":...}` + `{"ext":...}`
+	return append(append(data[:len(data)-1], ','), extensions[1:]...), nil
+}
+
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*problemJson)(p)); err != nil {
+		return err
+	}
+
+	var members map[string]interface{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	p.Extensions = nil
+	for key, value := range members {
+		if problemMembers[key] {
+			continue
+		}
+
+		if p.Extensions == nil {
+			p.Extensions = make(Metadata)
+		}
+		p.Extensions[key] = value
+	}
+
+	return nil
+}
+
+// ProblemRenderer renders errors as RFC 7807 (RFC 9457) problem details. Its Write method is an HTTPErrorWriter, so
+// it can be used by HTTPMiddleware and GetEchoProblemMiddleware.
+type ProblemRenderer struct {
+	// TypeBaseURI is prepended to the Type of the error to build the type member of the problem. Errors without Type
+	// will have "about:blank" as their type.
+	TypeBaseURI string
+
+	// ExtensionKeys selects which Metadata keys are sent to the client as extension members (none by default)
+	ExtensionKeys []string
+
+	// HideDetail prevents the message of errors from being sent to the client as the detail member. Only the message of
+	// the RichError itself is sent anyway, messages of the errors it wraps and errors that are not RichErrors may contain
+	// details that must not reach the client.
+	HideDetail bool
+}
+
+// Problem returns the problem details describing the given error. The path of the request is used to fill the
+// instance member (query strings may carry tokens, so they're left out) and the request can be nil.
+func (p ProblemRenderer) Problem(r *http.Request, err error) Problem {
+	problem := Problem{
+		Type:   "about:blank",
+		Status: http.StatusInternalServerError,
+	}
+
+	if r != nil && r.URL != nil {
+		problem.Instance = r.URL.Path
+	}
+
+	var rErr RichError
+	if errors.As(err, &rErr) {
+		problem.Status = rErr.Kind().HttpStatusCode()
+
+		if !p.HideDetail {
+			problem.Detail = ownMessage(rErr)
+		}
+
+		var violationsErr FieldViolationsError
+		if errors.As(err, &violationsErr) {
+			problem.InvalidParams = violationsErr.FieldViolations()
+		}
+
+		if rErr.Type() != nil {
+			problem.Type = p.TypeBaseURI + rErr.Type().String()
+			problem.Title = rErr.Type().String()
+		}
+
+		for _, key := range p.ExtensionKeys {
+			value, ok := rErr.Metadata()[key]
+			if !ok {
+				continue
+			}
+
+			if problem.Extensions == nil {
+				problem.Extensions = make(Metadata)
+			}
+			problem.Extensions[key] = value
+		}
+	}
+
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+
+	return problem
+}
+
+// Write writes the problem details describing the given error as an application/problem+json response
+func (p ProblemRenderer) Write(w http.ResponseWriter, r *http.Request, err error) {
+	writeProblem(w, p.Problem(r, err))
+}
+
+// writeProblem writes the given problem details as an application/problem+json response
+func writeProblem(w http.ResponseWriter, problem Problem) {
+	body, e := json.Marshal(problem)
+	if e != nil {
+		http.Error(w, problem.Title, problem.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	_, _ = w.Write(body)
+}
+
+// FromProblem rebuilds a RichError from the given problem details. Kind is determined by the status member, Type by
+// the title member (unless the problem type is "about:blank"), message by the detail member and Metadata by the
+// extension members.
+func FromProblem(problem Problem) *richError {
+	return fromProblem(problem, 1)
+}
+
+// fromProblem rebuilds a RichError from the given problem details, skip is the number of frames to skip above its
+// caller to reach the call site that will be recorded as the runtime info of the error
+func fromProblem(problem Problem, skip int) *richError {
+	message := problem.Detail
+	if message == "" {
+		message = problem.Title
+	}
+
+	err := newRichError(message, skip+1).
+		WithKind(KindFromHTTPStatus(problem.Status)).
+		WithFields(problem.Extensions).
+		WithFieldViolations(problem.InvalidParams...)
+
+	if problem.Type != "" && problem.Type != "about:blank" {
+		_type := problem.Title
+		if _type == "" {
+			_type = problem.Type
+		}
+		err = err.WithType(StringType(_type))
+	}
+
+	if problem.Instance != "" {
+		err = err.WithField("instance", problem.Instance)
+	}
+
+	return err
+}
+
+// FromProblemResponse reads the body of the given application/problem+json response and rebuilds a RichError from
+// it. It returns an error if the response is not a problem details response.
+func FromProblemResponse(resp *http.Response) (RichError, error) {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || !strings.EqualFold(mediaType, ProblemContentType) {
+		return nil, fmt.Errorf("unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var problem Problem
+	if err := json.Unmarshal(body, &problem); err != nil {
+		return nil, err
+	}
+
+	if problem.Status == 0 {
+		problem.Status = resp.StatusCode
+	}
+
+	return fromProblem(problem, 1), nil
+}
@@ -0,0 +1,284 @@
+package richerror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Clock tells the current time and runs functions after a while, it can be replaced in tests to make time dependent
+// behaviours deterministic
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f in its own goroutine after d has elapsed, like time.AfterFunc
+	AfterFunc(d time.Duration, f func()) ClockTimer
+}
+
+// ClockTimer is a timer created by a Clock, it's satisfied by *time.Timer
+type ClockTimer interface {
+	Stop() bool
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) ClockTimer {
+	return time.AfterFunc(d, f)
+}
+
+// RateLimitOptions configures a RateLimitedLogger, zero values are replaced by their defaults
+type RateLimitOptions struct {
+	// Rate is the number of similar errors per second that are logged once the burst is exhausted, 1 by default
+	Rate float64
+	// Burst is the number of similar errors that can be logged at once, 10 by default
+	Burst int
+	// Window is how long suppressed errors are counted before a summary of them is logged, 1 minute by default
+	Window time.Duration
+	// Clock is used to refill the token buckets and close the windows, the system clock by default
+	Clock Clock
+}
+
+// rateLimitBucket is the token bucket of a single fingerprint, along with the errors it has suppressed
+type rateLimitBucket struct {
+	tokens     float64
+	lastRefill time.Time
+
+	suppressed      int
+	suppressedSince time.Time
+	lastSuppressed  error
+	// timer closes the window once it has elapsed since the first suppressed error
+	timer ClockTimer
+}
+
+// Assert RateLimitedLogger implements ContextErrorLogger and ContextInfoLogger
+var _ ContextErrorLogger = &RateLimitedLogger{}
+var _ ContextInfoLogger = &RateLimitedLogger{}
+
+// RateLimitedLogger is an ErrorLogger that stops floods of similar errors (e.g. when a dependency is down) from
+// reaching the wrapped logger. Errors are considered similar if they have the same fingerprint (see
+// rateLimitFingerprint) and each fingerprint is limited by its own token bucket. Once the window of a fingerprint
+// closes (i.e. Window has elapsed since its first suppressed error), a single "suppressed N similar errors" error is
+// logged in place of the suppressed errors. Close must be called to log the summaries of the windows that are still
+// open and stop their timers.
+//
+// Fatal errors and info logs are never suppressed.
+type RateLimitedLogger struct {
+	logger  ErrorLogger
+	options RateLimitOptions
+
+	mu      sync.Mutex
+	buckets map[string]*rateLimitBucket
+	closed  bool
+}
+
+// NewRateLimitedLogger returns a RateLimitedLogger that logs using the given logger
+func NewRateLimitedLogger(logger ErrorLogger, options RateLimitOptions) *RateLimitedLogger {
+	if options.Rate <= 0 {
+		options.Rate = 1
+	}
+	if options.Burst <= 0 {
+		options.Burst = 10
+	}
+	if options.Window <= 0 {
+		options.Window = time.Minute
+	}
+	if options.Clock == nil {
+		options.Clock = systemClock{}
+	}
+
+	return &RateLimitedLogger{
+		logger:  logger,
+		options: options,
+		buckets: make(map[string]*rateLimitBucket),
+	}
+}
+
+func (l *RateLimitedLogger) Log(err error) {
+	l.LogCtx(context.Background(), err)
+}
+
+func (l *RateLimitedLogger) LogCtx(ctx context.Context, err error) {
+	var rErr RichError
+	if errors.As(err, &rErr) && rErr.Level() == Fatal {
+		l.Flush()
+		LogCtx(l.logger, ctx, err)
+		return
+	}
+
+	now := l.options.Clock.Now()
+	fingerprint := rateLimitFingerprint(err)
+
+	l.mu.Lock()
+	summaries := l.closeWindows(now)
+
+	bucket, ok := l.buckets[fingerprint]
+	if !ok {
+		bucket = &rateLimitBucket{tokens: float64(l.options.Burst), lastRefill: now}
+		l.buckets[fingerprint] = bucket
+	}
+	l.refill(bucket, now)
+
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	} else {
+		if bucket.suppressed == 0 {
+			bucket.suppressedSince = now
+			if !l.closed {
+				bucket.timer = l.options.Clock.AfterFunc(l.options.Window, func() {
+					l.closeWindow(fingerprint)
+				})
+			}
+		}
+		bucket.suppressed++
+		bucket.lastSuppressed = err
+	}
+	l.mu.Unlock()
+
+	for _, summary := range summaries {
+		l.logger.Log(summary)
+	}
+
+	if allowed {
+		LogCtx(l.logger, ctx, err)
+	}
+}
+
+func (l *RateLimitedLogger) LogInfo(msg string) {
+	l.logger.LogInfo(msg)
+}
+
+func (l *RateLimitedLogger) LogInfoWithMetadata(msg string, metadata ...interface{}) {
+	l.logger.LogInfoWithMetadata(msg, metadata...)
+}
+
+func (l *RateLimitedLogger) LogInfoCtx(ctx context.Context, msg string, metadata ...interface{}) {
+	LogInfoCtx(l.logger, ctx, msg, metadata...)
+}
+
+// Close logs the summaries of every fingerprint that has suppressed errors and stops the timers of their windows.
+// Errors logged after Close are still rate limited, but their summaries are only logged by Flush.
+func (l *RateLimitedLogger) Close() {
+	l.mu.Lock()
+	l.closed = true
+	l.mu.Unlock()
+
+	l.Flush()
+}
+
+// Flush logs the summaries of every fingerprint that has suppressed errors, regardless of its window
+func (l *RateLimitedLogger) Flush() {
+	l.mu.Lock()
+	var summaries []error
+	for _, bucket := range l.buckets {
+		if bucket.suppressed != 0 {
+			summaries = append(summaries, l.summary(bucket))
+		}
+	}
+	l.mu.Unlock()
+
+	for _, summary := range summaries {
+		l.logger.Log(summary)
+	}
+}
+
+// closeWindow logs the summary of the given fingerprint if its window has closed, it's called by the timer of the
+// window
+func (l *RateLimitedLogger) closeWindow(fingerprint string) {
+	l.mu.Lock()
+	bucket, ok := l.buckets[fingerprint]
+	if !ok || bucket.suppressed == 0 || l.options.Clock.Now().Sub(bucket.suppressedSince) < l.options.Window {
+		l.mu.Unlock()
+		return
+	}
+
+	summary := l.summary(bucket)
+	l.mu.Unlock()
+
+	l.logger.Log(summary)
+}
+
+// closeWindows returns the summaries of the fingerprints whose window has closed, and forgets the fingerprints that
+// have been idle for a whole window. It must be called with mu held.
+func (l *RateLimitedLogger) closeWindows(now time.Time) []error {
+	var summaries []error
+	for fingerprint, bucket := range l.buckets {
+		if bucket.suppressed != 0 && now.Sub(bucket.suppressedSince) >= l.options.Window {
+			summaries = append(summaries, l.summary(bucket))
+			continue
+		}
+
+		if bucket.suppressed == 0 && now.Sub(bucket.lastRefill) >= l.options.Window {
+			delete(l.buckets, fingerprint)
+		}
+	}
+
+	return summaries
+}
+
+// summary returns the error that is logged in place of the errors suppressed by the bucket and resets the bucket. It
+// must be called with mu held.
+func (l *RateLimitedLogger) summary(bucket *rateLimitBucket) error {
+	summary := New(fmt.Sprintf("suppressed %d similar errors", bucket.suppressed)).
+		WithError(bucket.lastSuppressed).
+		WithFields(Metadata{
+			"suppressed_count": bucket.suppressed,
+			"suppressed_since": bucket.suppressedSince,
+		})
+
+	var rErr RichError
+	if errors.As(bucket.lastSuppressed, &rErr) && len(rErr.RuntimeInfo()) != 0 {
+		summary.runtimeInfo[0] = rErr.RuntimeInfo()[len(rErr.RuntimeInfo())-1]
+	}
+
+	if bucket.timer != nil {
+		bucket.timer.Stop()
+	}
+
+	bucket.suppressed = 0
+	bucket.suppressedSince = time.Time{}
+	bucket.lastSuppressed = nil
+	bucket.timer = nil
+
+	return summary
+}
+
+// refill adds the tokens earned since the last refill to the bucket. It must be called with mu held.
+func (l *RateLimitedLogger) refill(bucket *rateLimitBucket, now time.Time) {
+	if elapsed := now.Sub(bucket.lastRefill); elapsed > 0 {
+		bucket.tokens += elapsed.Seconds() * l.options.Rate
+		if bucket.tokens > float64(l.options.Burst) {
+			bucket.tokens = float64(l.options.Burst)
+		}
+	}
+
+	bucket.lastRefill = now
+}
+
+// rateLimitFingerprint identifies similar errors by their Type, Kind, Operation and the runtime info of where they
+// originated (i.e. the innermost RichError). Errors that are not RichErrors are identified by their Go type and
+// message.
+func rateLimitFingerprint(err error) string {
+	var rErr RichError
+	if !errors.As(err, &rErr) {
+		return fmt.Sprintf("%T|%s", err, err.Error())
+	}
+
+	var _type string
+	if rErr.Type() != nil {
+		_type = rErr.Type().String()
+	}
+
+	var origin string
+	if runtimeInfo := rErr.RuntimeInfo(); len(runtimeInfo) != 0 {
+		originInfo := runtimeInfo[len(runtimeInfo)-1]
+		origin = fmt.Sprintf("%s:%d", originInfo.FileName, originInfo.LineNumber)
+	}
+
+	return fmt.Sprintf("%s|%s|%s|%s", _type, rErr.Kind(), rErr.Operation(), origin)
+}
@@ -0,0 +1,208 @@
+package richerror
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose time only moves when Advance is called
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock   *fakeClock
+	at      time.Time
+	f       func()
+	stopped bool
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) ClockTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	timer := &fakeTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+// Advance moves the time forward and calls the functions of the timers that have expired
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+
+	var expired, pending []*fakeTimer
+	for _, timer := range c.timers {
+		switch {
+		case timer.stopped:
+		case !timer.at.After(c.now):
+			expired = append(expired, timer)
+		default:
+			pending = append(pending, timer)
+		}
+	}
+	c.timers = pending
+	c.mu.Unlock()
+
+	sort.Slice(expired, func(i, j int) bool { return expired[i].at.Before(expired[j].at) })
+	for _, timer := range expired {
+		timer.f()
+	}
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	wasActive := !t.stopped
+	t.stopped = true
+	return wasActive
+}
+
+// recordingLogger is an ErrorLogger that keeps the logged errors in memory
+type recordingLogger struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *recordingLogger) Log(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errs = append(r.errs, err)
+}
+
+func (r *recordingLogger) LogInfo(string) {}
+
+func (r *recordingLogger) LogInfoWithMetadata(string, ...interface{}) {}
+
+func (r *recordingLogger) Messages() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	messages := make([]string, 0, len(r.errs))
+	for _, err := range r.errs {
+		messages = append(messages, err.(*richError).message)
+	}
+	return messages
+}
+
+func newTestRateLimitedLogger() (*RateLimitedLogger, *recordingLogger, *fakeClock) {
+	logger := &recordingLogger{}
+	clock := newFakeClock()
+	return NewRateLimitedLogger(logger, RateLimitOptions{Rate: 1, Burst: 2, Window: 10 * time.Second, Clock: clock}),
+		logger, clock
+}
+
+func dependencyDownError() *richError {
+	return New("dependency down").WithKind(Unavailable).WithOperation("db.query")
+}
+
+func assertMessages(t *testing.T, actual []string, expected ...string) {
+	t.Helper()
+
+	if len(actual) != len(expected) {
+		t.Fatalf("expected messages %q, got %q", expected, actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Fatalf("expected messages %q, got %q", expected, actual)
+		}
+	}
+}
+
+func TestRateLimitedLoggerSuppressesFloods(t *testing.T) {
+	rateLimited, logger, _ := newTestRateLimitedLogger()
+
+	for i := 0; i < 5; i++ {
+		rateLimited.Log(dependencyDownError())
+	}
+
+	assertMessages(t, logger.Messages(), "dependency down", "dependency down")
+}
+
+func TestRateLimitedLoggerRefillsTokens(t *testing.T) {
+	rateLimited, logger, clock := newTestRateLimitedLogger()
+
+	for i := 0; i < 3; i++ {
+		rateLimited.Log(dependencyDownError())
+	}
+	clock.Advance(time.Second)
+	rateLimited.Log(dependencyDownError())
+
+	assertMessages(t, logger.Messages(), "dependency down", "dependency down", "dependency down")
+}
+
+func TestRateLimitedLoggerLogsSummaryWhenWindowCloses(t *testing.T) {
+	rateLimited, logger, clock := newTestRateLimitedLogger()
+
+	for i := 0; i < 5; i++ {
+		rateLimited.Log(dependencyDownError())
+	}
+
+	clock.Advance(9 * time.Second)
+	assertMessages(t, logger.Messages(), "dependency down", "dependency down")
+
+	clock.Advance(time.Second)
+	assertMessages(t, logger.Messages(), "dependency down", "dependency down", "suppressed 3 similar errors")
+
+	summary := logger.errs[2].(RichError)
+	if summary.Metadata()["suppressed_count"] != 3 {
+		t.Errorf("expected suppressed_count 3, got %v", summary.Metadata()["suppressed_count"])
+	}
+	if summary.Kind() != Unavailable || summary.Operation() != "db.query" {
+		t.Errorf("expected the summary to keep kind and operation, got %s %s", summary.Kind(), summary.Operation())
+	}
+}
+
+func TestRateLimitedLoggerLimitsFingerprintsSeparately(t *testing.T) {
+	rateLimited, logger, _ := newTestRateLimitedLogger()
+
+	for i := 0; i < 3; i++ {
+		rateLimited.Log(dependencyDownError())
+		rateLimited.Log(New("other failure").WithKind(Internal))
+	}
+
+	assertMessages(t, logger.Messages(),
+		"dependency down", "other failure", "dependency down", "other failure")
+}
+
+func TestRateLimitedLoggerCloseLogsOpenWindows(t *testing.T) {
+	rateLimited, logger, clock := newTestRateLimitedLogger()
+
+	for i := 0; i < 4; i++ {
+		rateLimited.Log(dependencyDownError())
+	}
+	rateLimited.Close()
+	assertMessages(t, logger.Messages(), "dependency down", "dependency down", "suppressed 2 similar errors")
+
+	clock.Advance(time.Minute)
+	if len(logger.Messages()) != 3 {
+		t.Errorf("expected no summary to be logged after Close, got %q", logger.Messages())
+	}
+}
+
+func TestRateLimitedLoggerNeverSuppressesFatalErrors(t *testing.T) {
+	rateLimited, logger, _ := newTestRateLimitedLogger()
+
+	for i := 0; i < 3; i++ {
+		rateLimited.Log(New("fatal").WithLevel(Fatal))
+	}
+
+	assertMessages(t, logger.Messages(), "fatal", "fatal", "fatal")
+}
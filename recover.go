@@ -1,20 +1,38 @@
 package richerror
 
-import (
-	"fmt"
-	"runtime/debug"
-)
+import "net/http"
 
-func recoverAndReturnError(path string) error {
-	if r := recover(); r != nil {
-		errType := StringType(fmt.Sprintf("panic: %s", r))
-		err := New("panic detected").WithType(errType).WithFields(Metadata{
-			"path":        path,
-			"panic":       r,
-			"stack_trace": debug.Stack(),
-		})
-		return err
+// panicType is the Type of errors created from panics, the recovered value is only kept in their Metadata as it may
+// contain details that must not reach the clients
+var panicType = StringType("panic")
+
+// errorFromPanic converts the value recovered from a panic into a RichError. Keep in mind that recover only works
+// when it's called directly by the deferred function, so callers have to call it themselves and pass its result here.
+func errorFromPanic(path string, recovered interface{}) *richError {
+	return panicError(path, recovered, 1)
+}
+
+// httpErrorFromPanic works like errorFromPanic but panics again with http.ErrAbortHandler, which is used by handlers
+// to abort the response and should be handled by the http server
+func httpErrorFromPanic(path string, recovered interface{}) *richError {
+	if recovered == http.ErrAbortHandler {
+		panic(recovered)
+	}
+
+	return panicError(path, recovered, 1)
+}
+
+// panicError creates the RichError of a panic, skip is the number of frames to skip above its caller to reach the
+// deferred function that has recovered the panic
+func panicError(path string, recovered interface{}, skip int) *richError {
+	if recovered == nil {
+		return nil
 	}
 
-	return nil
+	err := newRichError("panic detected", skip+1).WithType(panicType).WithFields(Metadata{
+		"path":  path,
+		"panic": recovered,
+	})
+	err.stack = captureStack(skip+1, StackTraceDepth)
+	return err
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"runtime"
 	"strings"
+	"time"
 )
 
 type richError struct {
@@ -13,45 +14,128 @@ type richError struct {
 	fields       Metadata
 
 	runtimeInfo []RuntimeInfo
+	stack       *stack
 
 	_type     Type
 	level     Level
 	kind      Kind
 	operation Operation
+
+	fieldViolations []FieldViolation
+	retryDelay      time.Duration
+
+	// frozen errors are never modified, their With* methods return derived copies instead
+	frozen bool
+	// origin is the frozen error that this error has been derived from
+	origin *richError
+	// template is the ErrorTemplate that this error is an instance of
+	template *ErrorTemplate
 }
 
+// Assert richError implements StackTraceError, FieldViolationsError and RetryDelayError
+var _ StackTraceError = &richError{}
+var _ FieldViolationsError = &richError{}
+var _ RetryDelayError = &richError{}
+
 // New creates a new richError
 func New(message string) *richError {
-	pc, fileName, lineNumber, _ := runtime.Caller(1)
-
-	funcPt := runtime.FuncForPC(pc)
-	functionName := "Unknown"
-	if funcPt != nil {
-		functionName = funcPt.Name()
-	}
+	return newRichError(message, 1)
+}
 
-	return &richError{
+// newRichError creates a new richError, skip is the number of frames to skip above its caller to reach the call site
+// that will be recorded as the runtime info of the error
+func newRichError(message string, skip int) *richError {
+	err := &richError{
 		wrappedError: nil,
 		message:      message,
 		fields:       make(map[string]interface{}),
 
-		runtimeInfo: []RuntimeInfo{
-			{
-				LineNumber:   lineNumber,
-				FileName:     fileName,
-				FunctionName: functionName,
-			},
-		},
+		runtimeInfo: []RuntimeInfo{callerRuntimeInfo(skip + 1)},
 
 		_type:     nil,
 		level:     UnknownLevel,
 		kind:      UnknownKind,
 		operation: "",
 	}
+
+	if CaptureStackTrace {
+		err.stack = captureStack(skip+1, StackTraceDepth)
+	}
+
+	return err
+}
+
+// callerRuntimeInfo returns the runtime information of its caller, skip is the number of frames to skip above the caller
+func callerRuntimeInfo(skip int) RuntimeInfo {
+	pc, fileName, lineNumber, _ := runtime.Caller(skip + 1)
+
+	funcPt := runtime.FuncForPC(pc)
+	functionName := "Unknown"
+	if funcPt != nil {
+		functionName = funcPt.Name()
+	}
+
+	return RuntimeInfo{
+		LineNumber:   lineNumber,
+		FileName:     fileName,
+		FunctionName: functionName,
+	}
+}
+
+// Freeze makes the error immutable, which makes it safe to be shared (e.g. as a package level sentinel error). With*
+// methods of a frozen error don't modify it, instead they return a new error derived from it. Derived errors record
+// the call site of their derivation and satisfy errors.Is(derived, frozen).
+//
+//	var ErrNotFound = richerror.New("not found").WithKind(richerror.NotFound).Freeze()
+//
+//	return ErrNotFound.WithField("id", id)
+func (r *richError) Freeze() *richError {
+	r.frozen = true
+	return r
+}
+
+// mutable returns the error itself, or a new error derived from it if the error is frozen. It must be called
+// directly by With* methods, so the derivation call site can be recorded.
+func (r *richError) mutable() *richError {
+	if !r.frozen {
+		return r
+	}
+
+	fields := make(Metadata, len(r.fields))
+	for key, value := range r.fields {
+		fields[key] = value
+	}
+
+	derived := &richError{
+		wrappedError: r.wrappedError,
+		message:      r.message,
+		fields:       fields,
+
+		runtimeInfo: append([]RuntimeInfo{callerRuntimeInfo(2)}, r.runtimeInfo...),
+		stack:       r.stack,
+
+		_type:     r._type,
+		level:     r.level,
+		kind:      r.kind,
+		operation: r.operation,
+
+		fieldViolations: append([]FieldViolation(nil), r.fieldViolations...),
+		retryDelay:      r.retryDelay,
+
+		origin:   r,
+		template: r.template,
+	}
+
+	if CaptureStackTrace {
+		derived.stack = captureStack(2, StackTraceDepth)
+	}
+
+	return derived
 }
 
 // WithFields appends given fields to already existing ones
 func (r *richError) WithFields(fields Metadata) *richError {
+	r = r.mutable()
 	for key, value := range fields {
 		r.fields[key] = value
 	}
@@ -60,40 +144,82 @@ func (r *richError) WithFields(fields Metadata) *richError {
 
 // WithField appends given field to already existing ones
 func (r *richError) WithField(key string, value interface{}) *richError {
+	r = r.mutable()
 	r.fields[key] = value
 	return r
 }
 
 // WithType specifies type of the error
 func (r *richError) WithType(_type Type) *richError {
+	r = r.mutable()
 	r._type = _type
 	return r
 }
 
 // WithLevel assigns an error level to the error which can be used for log purposes
 func (r *richError) WithLevel(level Level) *richError {
+	r = r.mutable()
 	r.level = level
 	return r
 }
 
 // WithKind assigns an error kind to the error which can be used to decide the return code of the failed request
 func (r *richError) WithKind(kind Kind) *richError {
+	r = r.mutable()
 	r.kind = kind
 	return r
 }
 
 func (r *richError) WithOperation(operation Operation) *richError {
+	r = r.mutable()
 	r.operation = operation
 	return r
 }
 
-// WithError wraps the underlying error and copies level, kind, type, and operation of the underlying error if not explicitly specified
+// WithStackTrace captures the full stack trace of where it's been called (regardless of CaptureStackTrace), depth
+// limits the number of captured frames, if it's not positive StackTraceDepth will be used
+func (r *richError) WithStackTrace(depth int) *richError {
+	r = r.mutable()
+	r.stack = captureStack(1, depth)
+	return r
+}
+
+// WithFieldViolation appends a violation of the given request field to already existing ones
+func (r *richError) WithFieldViolation(field, description string) *richError {
+	r = r.mutable()
+	r.fieldViolations = append(r.fieldViolations, FieldViolation{Field: field, Description: description})
+	return r
+}
+
+// WithFieldViolations appends given field violations to already existing ones
+func (r *richError) WithFieldViolations(violations ...FieldViolation) *richError {
+	r = r.mutable()
+	r.fieldViolations = append(r.fieldViolations, violations...)
+	return r
+}
+
+// WithRetryDelay hints the caller how long it should wait before retrying the failed request
+func (r *richError) WithRetryDelay(delay time.Duration) *richError {
+	r = r.mutable()
+	r.retryDelay = delay
+	return r
+}
+
+// WithError wraps the underlying error and copies level, kind, type, and operation of the underlying error if not explicitly specified.
+// If the underlying error is not a RichError its kind is determined using KindFromError.
 func (r *richError) WithError(err error) *richError {
+	r = r.mutable()
 	r.wrappedError = err
 
 	var wrappedRichError RichError
 	ok := errors.As(err, &wrappedRichError)
 	if !ok {
+		if r.kind == UnknownKind {
+			if kind := KindFromError(err); kind != Unknown {
+				r.kind = kind
+			}
+		}
+
 		return r
 	}
 
@@ -113,6 +239,16 @@ func (r *richError) WithError(err error) *richError {
 		r._type = wrappedRichError.Type()
 	}
 
+	var violationsErr FieldViolationsError
+	if len(r.fieldViolations) == 0 && errors.As(err, &violationsErr) {
+		r.fieldViolations = append([]FieldViolation(nil), violationsErr.FieldViolations()...)
+	}
+
+	var retryErr RetryDelayError
+	if r.retryDelay == 0 && errors.As(err, &retryErr) {
+		r.retryDelay = retryErr.RetryDelay()
+	}
+
 	for key, value := range wrappedRichError.Metadata() {
 		if _, ok := r.fields[key]; !ok {
 			r.fields[key] = value
@@ -165,6 +301,14 @@ func (r *richError) string(step int) string {
 		msg += fmt.Sprintf("fileds: %+v ", r.fields)
 	}
 
+	if len(r.fieldViolations) != 0 {
+		msg += fmt.Sprintf("field_violations: %+v ", r.fieldViolations)
+	}
+
+	if r.retryDelay != 0 {
+		msg += fmt.Sprintf("retry_delay: %s ", r.retryDelay)
+	}
+
 	msg += fmt.Sprintf("code_info: %s ", r.runtimeInfo[0].String())
 
 	if r.wrappedError != nil {
@@ -173,6 +317,20 @@ func (r *richError) string(step int) string {
 			return fmt.Sprintf("%s%s\n%s", strings.Repeat("\t", step), msg, innerError.string(step+1))
 		}
 
+		list, ok := r.wrappedError.(*errorList)
+		if ok {
+			for _, err := range list.errs {
+				if innerError, ok := err.(*richError); ok {
+					msg += "\n" + strings.TrimSuffix(innerError.string(step+1), "\n")
+					continue
+				}
+
+				msg += fmt.Sprintf("\n%smessage: %s", strings.Repeat("\t", step+1), err.Error())
+			}
+
+			return fmt.Sprintf("%s%s\n", strings.Repeat("\t", step), msg)
+		}
+
 		return fmt.Sprintf("%s%s\n%smessage: %s\n", strings.Repeat("\t", step), msg,
 			strings.Repeat("\t", step+1), r.wrappedError.Error())
 	}
@@ -192,12 +350,35 @@ func (r *richError) Unwrap() error {
 	return r.wrappedError
 }
 
+// Is reports whether the error has been derived from target (see Freeze), or it's an instance of target (see
+// ErrorTemplate), or both the error and target are instances of the same template. errors.Is takes care of the
+// wrapped errors.
 func (r *richError) Is(target error) bool {
-	return errors.Is(r, target)
+	if r.template != nil {
+		switch target := target.(type) {
+		case *ErrorTemplate:
+			if r.template == target {
+				return true
+			}
+		case *richError:
+			if r.template == target.template {
+				return true
+			}
+		}
+	}
+
+	for origin := r.origin; origin != nil; origin = origin.origin {
+		if origin == target {
+			return true
+		}
+	}
+
+	return false
 }
 
-func (r *richError) As(target interface{}) bool {
-	return errors.As(r, &target)
+// As doesn't match anything by itself, errors.As takes care of the error and its wrapped errors
+func (r *richError) As(interface{}) bool {
+	return false
 }
 
 func (r *richError) Metadata() Metadata {
@@ -208,13 +389,23 @@ func (r *richError) RuntimeInfo() []RuntimeInfo {
 	return r.runtimeInfo
 }
 
+// StackTrace returns the stack trace captured when the error has been created (see CaptureStackTrace and
+// WithStackTrace), the innermost frame comes first. It returns nil if no stack trace has been captured.
+func (r *richError) StackTrace() []RuntimeInfo {
+	return r.stack.Frames()
+}
+
 func (r *richError) Operation() Operation {
 	return r.operation
 }
 
 func (r *richError) Level() Level {
 	if r.level == UnknownLevel {
-		return Error
+		if list, ok := r.wrappedError.(*errorList); ok {
+			return list.level()
+		}
+
+		return r.Kind().DefaultLevel()
 	}
 
 	return r.level
@@ -226,12 +417,24 @@ func (r *richError) Type() Type {
 
 func (r *richError) Kind() Kind {
 	if r.kind == UnknownKind {
+		if list, ok := r.wrappedError.(*errorList); ok && list.kind() != UnknownKind {
+			return list.kind()
+		}
+
 		return Unknown
 	}
 
 	return r.kind
 }
 
+func (r *richError) FieldViolations() []FieldViolation {
+	return r.fieldViolations
+}
+
+func (r *richError) RetryDelay() time.Duration {
+	return r.retryDelay
+}
+
 // Deprecated: CodeInfo has been renamed to RuntimeInfo and will be removed in V2
 func (r *richError) CodeInfo() CodeInfo {
 	return CodeInfo{
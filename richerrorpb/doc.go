@@ -0,0 +1,5 @@
+// Package richerrorpb contains the protobuf representation of RichError which is used to carry RichErrors in gRPC
+// status details.
+package richerrorpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative richerror.proto
@@ -0,0 +1,275 @@
+package richerror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrorFilter selects the errors that are sent to the logger of a Route. An error matches the filter if it satisfies
+// every condition that has been set, so the zero ErrorFilter matches everything. Errors that are not RichErrors are
+// considered to have the Error level and the Kind given by KindFromError.
+type ErrorFilter struct {
+	// MinLevel rejects the errors that are less severe than it (e.g. Error only matches Error and Fatal)
+	MinLevel Level
+	// Kinds rejects the errors whose Kind is not one of them
+	Kinds []Kind
+	// ExcludeKinds rejects the errors whose Kind is one of them
+	ExcludeKinds []Kind
+	// Operation rejects the errors whose Operation doesn't match it, it's a glob pattern where * matches any sequence of
+	// characters (including the / and . of names like /pkg.Service/Method) and ? matches a single character
+	Operation string
+	// Type rejects the errors whose Type (its string representation) is not equal to it
+	Type string
+	// Predicate rejects the errors for which it returns false, errors that are not RichErrors are always rejected
+	Predicate func(RichError) bool
+}
+
+// Match reports whether the error matches the filter
+func (f ErrorFilter) Match(err error) bool {
+	var rErr RichError
+	if !errors.As(err, &rErr) {
+		return f.Predicate == nil && f.match(Error, KindFromError(err), "", "")
+	}
+
+	var _type string
+	if rErr.Type() != nil {
+		_type = rErr.Type().String()
+	}
+
+	if !f.match(rErr.Level(), rErr.Kind(), rErr.Operation(), _type) {
+		return false
+	}
+
+	return f.Predicate == nil || f.Predicate(rErr)
+}
+
+// matchInfo reports whether info logs match the filter, they're considered to have the Info level and no Kind
+func (f ErrorFilter) matchInfo() bool {
+	return f.Predicate == nil && f.match(Info, Unknown, "", "")
+}
+
+func (f ErrorFilter) match(level Level, kind Kind, operation Operation, _type string) bool {
+	if f.MinLevel != UnknownLevel && (level == UnknownLevel || level > f.MinLevel) {
+		return false
+	}
+
+	if len(f.Kinds) != 0 && !containsKind(f.Kinds, kind) {
+		return false
+	}
+
+	if containsKind(f.ExcludeKinds, kind) {
+		return false
+	}
+
+	if f.Operation != "" && !matchOperation(f.Operation, string(operation)) {
+		return false
+	}
+
+	return f.Type == "" || f.Type == _type
+}
+
+// matchOperation reports whether the operation matches the glob pattern (see ErrorFilter.Operation). Unlike path.Match
+// the stars match slashes as well, since operations are names rather than paths.
+func matchOperation(pattern, operation string) bool {
+	for len(pattern) != 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(operation); i >= 0; i-- {
+				if matchOperation(pattern[1:], operation[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(operation) == 0 {
+				return false
+			}
+			_, size := utf8.DecodeRuneInString(operation)
+			operation = operation[size:]
+		default:
+			if len(operation) == 0 || operation[0] != pattern[0] {
+				return false
+			}
+			operation = operation[1:]
+		}
+
+		pattern = pattern[1:]
+	}
+
+	return len(operation) == 0
+}
+
+func containsKind(kinds []Kind, kind Kind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Route sends the errors that match Filter to Logger
+type Route struct {
+	Logger ErrorLogger
+	Filter ErrorFilter
+}
+
+// Assert RoutingLogger implements ContextErrorLogger and ContextInfoLogger
+var _ ContextErrorLogger = RoutingLogger{}
+var _ ContextInfoLogger = RoutingLogger{}
+
+// RoutingLogger is an ErrorLogger that sends each error to the loggers of every route whose filter matches it (unlike
+// ChainLogger which sends every error to every logger). Info logs are sent to the routes that accept the Info level
+// and don't filter by Kind, Operation, Type, or Predicate.
+//
+//	richerror.RoutingLogger{Routes: []richerror.Route{
+//		{Logger: stdoutLogger},
+//		{Logger: sentryLogger, Filter: richerror.ErrorFilter{
+//			MinLevel:     richerror.Error,
+//			ExcludeKinds: []richerror.Kind{richerror.InvalidArgument},
+//		}},
+//	}}
+type RoutingLogger struct {
+	Routes []Route
+}
+
+func (r RoutingLogger) Log(err error) {
+	r.LogCtx(context.Background(), err)
+}
+
+func (r RoutingLogger) LogCtx(ctx context.Context, err error) {
+	for _, route := range r.Routes {
+		if route.Filter.Match(err) {
+			LogCtx(route.Logger, ctx, err)
+		}
+	}
+}
+
+func (r RoutingLogger) LogInfo(msg string) {
+	for _, route := range r.Routes {
+		if route.Filter.matchInfo() {
+			route.Logger.LogInfo(msg)
+		}
+	}
+}
+
+func (r RoutingLogger) LogInfoWithMetadata(msg string, metadata ...interface{}) {
+	for _, route := range r.Routes {
+		if route.Filter.matchInfo() {
+			route.Logger.LogInfoWithMetadata(msg, metadata...)
+		}
+	}
+}
+
+func (r RoutingLogger) LogInfoCtx(ctx context.Context, msg string, metadata ...interface{}) {
+	for _, route := range r.Routes {
+		if route.Filter.matchInfo() {
+			LogInfoCtx(route.Logger, ctx, msg, metadata...)
+		}
+	}
+}
+
+// RoutingConfig describes the routes of a RoutingLogger, so they can be loaded from a configuration file (see
+// NewRoutingLogger)
+//
+//	routes:
+//	  - sink: stdout
+//	  - sink: sentry
+//	    min_level: Error
+//	    exclude_kinds: [InvalidArgument]
+type RoutingConfig struct {
+	Routes []RouteConfig `json:"routes" yaml:"routes"`
+}
+
+// RouteConfig describes a single Route, levels and kinds are referred to by their names (e.g. "Error" and
+// "InvalidArgument", case and spaces of kind names are ignored) and Sink is the name of the logger
+type RouteConfig struct {
+	Sink         string   `json:"sink" yaml:"sink"`
+	MinLevel     string   `json:"min_level,omitempty" yaml:"min_level,omitempty"`
+	Kinds        []string `json:"kinds,omitempty" yaml:"kinds,omitempty"`
+	ExcludeKinds []string `json:"exclude_kinds,omitempty" yaml:"exclude_kinds,omitempty"`
+	Operation    string   `json:"operation,omitempty" yaml:"operation,omitempty"`
+	Type         string   `json:"type,omitempty" yaml:"type,omitempty"`
+}
+
+// NewRoutingLogger builds a RoutingLogger from the given config, sinks maps the names used by the config to loggers
+func NewRoutingLogger(config RoutingConfig, sinks map[string]ErrorLogger) (RoutingLogger, error) {
+	routes := make([]Route, 0, len(config.Routes))
+	for _, routeConfig := range config.Routes {
+		route, err := routeConfig.route(sinks)
+		if err != nil {
+			return RoutingLogger{}, err
+		}
+
+		routes = append(routes, route)
+	}
+
+	return RoutingLogger{Routes: routes}, nil
+}
+
+func (c RouteConfig) route(sinks map[string]ErrorLogger) (Route, error) {
+	logger, ok := sinks[c.Sink]
+	if !ok {
+		return Route{}, fmt.Errorf("unknown sink %q", c.Sink)
+	}
+
+	filter := ErrorFilter{Operation: c.Operation, Type: c.Type}
+
+	if c.MinLevel != "" {
+		filter.MinLevel = levelFromString(c.MinLevel)
+		if filter.MinLevel == UnknownLevel {
+			return Route{}, fmt.Errorf("unknown level %q", c.MinLevel)
+		}
+	}
+
+	var err error
+	if filter.Kinds, err = kindsByName(c.Kinds); err != nil {
+		return Route{}, err
+	}
+	if filter.ExcludeKinds, err = kindsByName(c.ExcludeKinds); err != nil {
+		return Route{}, err
+	}
+
+	return Route{Logger: logger, Filter: filter}, nil
+}
+
+func kindsByName(names []string) ([]Kind, error) {
+	kinds := make([]Kind, 0, len(names))
+	for _, name := range names {
+		kind, ok := kindByConfigName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown kind %q", name)
+		}
+
+		kinds = append(kinds, kind)
+	}
+
+	return kinds, nil
+}
+
+// kindByConfigName returns the Kind with the given name ignoring case and spaces, so both "Invalid Argument" and
+// "InvalidArgument" refer to InvalidArgument
+func kindByConfigName(name string) (Kind, bool) {
+	if kind, ok := KindByName(name); ok {
+		return kind, true
+	}
+
+	normalize := func(name string) string {
+		return strings.ToLower(strings.Replace(name, " ", "", -1))
+	}
+
+	kindsMu.RLock()
+	defer kindsMu.RUnlock()
+
+	for i, info := range kinds {
+		if Kind(i) != UnknownKind && normalize(info.Name) == normalize(name) {
+			return Kind(i), true
+		}
+	}
+
+	return UnknownKind, false
+}
@@ -1,48 +1,178 @@
 package richerror
 
 import (
+	"context"
 	"errors"
+	"runtime"
 	"time"
 
 	"github.com/getsentry/sentry-go"
 )
 
-var _ ErrorLogger = SentryLogger{}
+var _ ContextErrorLogger = SentryLogger{}
+var _ ContextInfoLogger = SentryLogger{}
 
 // SentryLogger is a ErrorLogger that logs to sentry. The returned ErrorLogger will report details of your errors (if
-// they're RichError) to the sentry using `sentry-go` module.
+// they're RichError) to the sentry using `sentry-go` module. Info logs are recorded as breadcrumbs, use LogInfoCtx to
+// record them on the hub of the request instead of the current hub.
 type SentryLogger struct {
 	Environment string
 	ServerName  string
 }
 
 func (s SentryLogger) Log(err error) {
-	sentryHub := sentry.CurrentHub().Clone()
+	s.LogCtx(context.Background(), err)
+}
+
+// LogCtx reports the error along with the fields stored in ctx (see ContextWithFields) through the hub of the request
+// that the interceptors of this package store in ctx, or the sentry hub stored in ctx (see sentry.SetHubOnContext), or
+// the current hub. Every layer of the wrap chain of the error is reported as an exception, and RichErrors are grouped
+// by their Type, Kind and Operation (see sentryFingerprint).
+func (s SentryLogger) LogCtx(ctx context.Context, err error) {
+	sentryHub := sentryHubFromContext(ctx)
+	contextFields := FieldsFromContext(ctx)
+
+	event := sentry.NewEvent()
+
+	event.Environment = s.Environment
+	event.Exception = sentryExceptions(err)
+	event.Level = sentry.LevelError
+	event.Message = err.Error()
+	event.ServerName = s.ServerName
+	event.Timestamp = time.Now()
 
 	var rErr RichError
 	ok := errors.As(err, &rErr)
 	if !ok {
-		sentryHub.CaptureException(err)
+		for key, value := range contextFields {
+			event.Extra[key] = value
+		}
+
+		sentryHub.CaptureEvent(event)
 		return
 	}
 
-	event := sentry.NewEvent()
-
-	event.Contexts = rErr.Metadata()
-	event.Environment = s.Environment
+	for key, value := range mergeFields(rErr.Metadata(), contextFields) {
+		event.Contexts[key] = value
+	}
+	event.Fingerprint = sentryFingerprint(rErr)
 	event.Level = rErr.Level().SentryLevel()
-	event.Message = rErr.Error()
-	event.ServerName = s.ServerName
-	event.Timestamp = time.Now()
 
 	event.Tags["kind"] = rErr.Kind().String()
 	if rErr.Operation() != "" {
 		event.Tags["operation"] = string(rErr.Operation())
 	}
+	if rErr.Type() != nil {
+		event.Tags["type"] = rErr.Type().String()
+	}
 
 	sentryHub.CaptureEvent(event)
 }
 
-func (s SentryLogger) LogInfo(string) {}
+// sentryExceptions converts the wrap chain of the error to sentry exceptions, sentry expects the innermost error to
+// come first and uses the last one as the title of the issue
+func sentryExceptions(err error) []sentry.Exception {
+	chain := ErrorChain(err)
+
+	exceptions := make([]sentry.Exception, 0, len(chain))
+	for i := len(chain) - 1; i >= 0; i-- {
+		frame := chain[i]
+
+		exception := sentry.Exception{
+			Type:  frame.GoType,
+			Value: frame.Message,
+		}
+
+		switch {
+		case frame.Type != "":
+			exception.Type = frame.Type
+		case frame.GoType == "" && frame.Kind != UnknownKind:
+			exception.Type = frame.Kind.String()
+		case frame.GoType == "":
+			exception.Type = Unknown.String()
+		}
 
-func (s SentryLogger) LogInfoWithMetadata(string, ...interface{}) {}
+		switch {
+		case len(frame.StackTrace) != 0:
+			exception.Stacktrace = sentryStacktrace(frame.StackTrace)
+		case frame.RuntimeInfo != nil:
+			exception.Stacktrace = sentryStacktrace([]RuntimeInfo{*frame.RuntimeInfo})
+		}
+
+		exceptions = append(exceptions, exception)
+	}
+
+	return exceptions
+}
+
+// sentryFingerprint returns the fingerprint that sentry uses to group the error into issues. Errors that have neither a
+// Type nor an Operation are grouped by their Kind and sentry's default grouping (i.e. by their stack trace).
+func sentryFingerprint(err RichError) []string {
+	if err.Type() == nil && err.Operation() == "" {
+		return []string{"{{ default }}", err.Kind().String()}
+	}
+
+	fingerprint := []string{err.Kind().String(), string(err.Operation())}
+	if err.Type() != nil {
+		fingerprint = append(fingerprint, err.Type().String())
+	}
+
+	return fingerprint
+}
+
+// sentryStacktrace converts the given frames to a sentry stacktrace, sentry expects the outermost frame to come first
+func sentryStacktrace(frames []RuntimeInfo) *sentry.Stacktrace {
+	stacktrace := &sentry.Stacktrace{Frames: make([]sentry.Frame, 0, len(frames))}
+	for i := len(frames) - 1; i >= 0; i-- {
+		stacktrace.Frames = append(stacktrace.Frames, sentry.NewFrame(runtime.Frame{
+			Function: frames[i].FunctionName,
+			File:     frames[i].FileName,
+			Line:     frames[i].LineNumber,
+		}))
+	}
+
+	return stacktrace
+}
+
+// LogInfo records the message as a breadcrumb on the current hub, which will be reported along with the next error
+func (s SentryLogger) LogInfo(msg string) {
+	s.LogInfoCtx(context.Background(), msg)
+}
+
+// LogInfoWithMetadata records the message and the given key value pairs as a breadcrumb on the current hub, which will
+// be reported along with the next error
+func (s SentryLogger) LogInfoWithMetadata(msg string, metadata ...interface{}) {
+	s.LogInfoCtx(context.Background(), msg, metadata...)
+}
+
+// LogInfoCtx records the message and the given key value pairs as a breadcrumb on the sentry hub stored in ctx (or the
+// current hub), so it will only be reported along with the errors of the same request
+func (s SentryLogger) LogInfoCtx(ctx context.Context, msg string, metadata ...interface{}) {
+	breadcrumb := &sentry.Breadcrumb{
+		Type:      "default",
+		Category:  "log",
+		Message:   msg,
+		Level:     sentry.LevelInfo,
+		Timestamp: time.Now(),
+	}
+
+	if len(metadata) != 0 {
+		breadcrumb.Data = metadataFromKeyValues(metadata)
+	}
+
+	sentryHubFromContext(ctx).AddBreadcrumb(breadcrumb, nil)
+}
+
+// sentryHubFromContext returns the hub of the request stored in ctx by the interceptors of this package, or the sentry
+// hub stored in ctx, or the current hub if there is none
+func sentryHubFromContext(ctx context.Context) *sentry.Hub {
+	if scope, ok := ctx.Value(sentryScopeKey{}).(*sentryRequestScope); ok {
+		return scope.Hub()
+	}
+
+	if hub := sentry.GetHubFromContext(ctx); hub != nil {
+		return hub
+	}
+
+	return sentry.CurrentHub()
+}
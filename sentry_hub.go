@@ -0,0 +1,145 @@
+package richerror
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/getsentry/sentry-go"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// SensitiveHeaders are the request headers (or gRPC metadata keys) whose values are filtered out of the copy of the
+// headers that the interceptors attach to the sentry scope of each request
+var SensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+const filteredHeaderValue = "[Filtered]"
+
+// sentryScopeKey is the context key of the sentryRequestScope of a request
+type sentryScopeKey struct{}
+
+// sentryRequestScope builds the sentry hub of a request the first time it's needed, so requests that don't report
+// anything to sentry don't pay for cloning the hub and copying the request data
+type sentryRequestScope struct {
+	once      sync.Once
+	ctx       context.Context
+	configure func(*sentry.Scope)
+	hub       *sentry.Hub
+}
+
+// Hub returns the hub of the request, a copy of the hub stored in the request context (or the current hub) whose
+// scope describes the request
+func (s *sentryRequestScope) Hub() *sentry.Hub {
+	s.once.Do(func() {
+		if hub := sentry.GetHubFromContext(s.ctx); hub != nil {
+			s.hub = hub.Clone()
+		} else {
+			s.hub = sentry.CurrentHub().Clone()
+		}
+
+		s.configure(s.hub.Scope())
+		s.ctx, s.configure = nil, nil
+	})
+
+	return s.hub
+}
+
+// contextWithSentryScope stores a sentryRequestScope in ctx, configure describes the request on the scope of its hub
+func contextWithSentryScope(ctx context.Context, configure func(*sentry.Scope)) context.Context {
+	return context.WithValue(ctx, sentryScopeKey{}, &sentryRequestScope{ctx: ctx, configure: configure})
+}
+
+// contextWithSentryHTTPHub stores a sentry hub scoped to the given http request in ctx, SentryLogger reports the
+// errors of the request through this hub. peerAddress is the address of the client and transaction is the name of
+// the route (if known). The hub is only built once it's needed.
+func contextWithSentryHTTPHub(ctx context.Context, r *http.Request, peerAddress, transaction string) context.Context {
+	return contextWithSentryScope(ctx, func(scope *sentry.Scope) {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+
+		request := &sentry.Request{
+			URL:         scheme + "://" + r.Host + r.URL.Path,
+			Method:      r.Method,
+			QueryString: r.URL.RawQuery,
+			Headers:     sanitizeHeaders(r.Header),
+			Env:         map[string]string{"REMOTE_ADDR": peerAddress},
+		}
+		scope.AddEventProcessor(func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+			if event.Request == nil {
+				event.Request = request
+			}
+			return event
+		})
+
+		if transaction == "" {
+			transaction = r.URL.Path
+		}
+		scope.SetTransaction(transaction)
+		setSentryUser(scope, ctx, peerAddress)
+	})
+}
+
+// contextWithSentryGRPCHub stores a sentry hub scoped to the given gRPC call in ctx, SentryLogger reports the errors
+// of the call through this hub. The hub is only built once it's needed.
+func contextWithSentryGRPCHub(ctx context.Context, method string) context.Context {
+	return contextWithSentryScope(ctx, func(scope *sentry.Scope) {
+		var peerAddress string
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			peerAddress = p.Addr.String()
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		scope.SetContext("grpc", map[string]interface{}{
+			"method":   method,
+			"peer":     peerAddress,
+			"metadata": sanitizeHeaders(md),
+		})
+		scope.SetTransaction(method)
+		setSentryUser(scope, ctx, peerAddress)
+	})
+}
+
+// setSentryUser identifies the user of the request by the user_id field stored in ctx (see DefaultContextHeaders) and
+// the address of the client
+func setSentryUser(scope *sentry.Scope, ctx context.Context, peerAddress string) {
+	user := sentry.User{IPAddress: peerAddress}
+	if host, _, err := net.SplitHostPort(peerAddress); err == nil {
+		user.IPAddress = host
+	}
+
+	if userID, ok := FieldsFromContext(ctx)["user_id"].(string); ok {
+		user.ID = userID
+	}
+
+	scope.SetUser(user)
+}
+
+// sanitizeHeaders returns a copy of the given headers (or gRPC metadata) in which values of SensitiveHeaders have been
+// filtered out
+func sanitizeHeaders(headers map[string][]string) map[string]string {
+	sanitized := make(map[string]string, len(headers))
+	for key, values := range headers {
+		value := strings.Join(values, ", ")
+		for _, sensitiveHeader := range SensitiveHeaders {
+			if strings.EqualFold(key, sensitiveHeader) {
+				value = filteredHeaderValue
+				break
+			}
+		}
+		sanitized[key] = value
+	}
+
+	return sanitized
+}
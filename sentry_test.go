@@ -0,0 +1,231 @@
+package richerror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// fakeSentryTransport is a sentry.Transport that keeps the events in memory instead of sending them
+type fakeSentryTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *fakeSentryTransport) Configure(sentry.ClientOptions) {}
+
+func (t *fakeSentryTransport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.events = append(t.events, event)
+}
+
+func (t *fakeSentryTransport) Flush(time.Duration) bool {
+	return true
+}
+
+func (t *fakeSentryTransport) Events() []*sentry.Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]*sentry.Event(nil), t.events...)
+}
+
+// initFakeSentry initializes sentry with a fake transport, each test gets its own current hub
+func initFakeSentry(t *testing.T) *fakeSentryTransport {
+	transport := &fakeSentryTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Dsn: "https://public@example.com/1", Transport: transport})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous := sentry.CurrentHub().Clone()
+	sentry.CurrentHub().BindClient(client)
+	sentry.CurrentHub().Scope().Clear()
+	t.Cleanup(func() {
+		sentry.CurrentHub().BindClient(previous.Client())
+		sentry.CurrentHub().Scope().Clear()
+	})
+
+	return transport
+}
+
+func TestSentryLoggerReportsWrapChain(t *testing.T) {
+	transport := initFakeSentry(t)
+
+	inner := New("query failed").WithKind(Unavailable).WithError(errors.New("connection refused"))
+	err := New("load user").WithOperation("users.get").WithType(StringType("UserNotLoaded")).WithError(inner)
+	SentryLogger{}.Log(err)
+
+	events := transport.Events()
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	event := events[0]
+
+	expectedExceptions := []struct{ _type, value string }{
+		{"*errors.errorString", "connection refused"},
+		{"Unavailable", "query failed"},
+		{"UserNotLoaded", "load user"},
+	}
+	if len(event.Exception) != len(expectedExceptions) {
+		t.Fatalf("expected %d exceptions, got %d", len(expectedExceptions), len(event.Exception))
+	}
+	for i, expected := range expectedExceptions {
+		exception := event.Exception[i]
+		if exception.Type != expected._type || exception.Value != expected.value {
+			t.Errorf("expected exception %d to be %s: %s, got %s: %s", i, expected._type, expected.value,
+				exception.Type, exception.Value)
+		}
+	}
+	if event.Exception[2].Stacktrace == nil || len(event.Exception[2].Stacktrace.Frames) == 0 {
+		t.Error("expected the outermost exception to have stack frames")
+	}
+
+	expectedFingerprint := fmt.Sprint([]string{"Unavailable", "users.get", "UserNotLoaded"})
+	if fmt.Sprint(event.Fingerprint) != expectedFingerprint {
+		t.Errorf("expected fingerprint %s, got %v", expectedFingerprint, event.Fingerprint)
+	}
+
+	tags := event.Tags
+	if tags["kind"] != "Unavailable" || tags["operation"] != "users.get" || tags["type"] != "UserNotLoaded" {
+		t.Errorf("unexpected tags %v", event.Tags)
+	}
+}
+
+func TestSentryLoggerRecordsInfoLogsAsBreadcrumbsOfTheirContext(t *testing.T) {
+	transport := initFakeSentry(t)
+
+	logger := SentryLogger{}
+	first := sentry.SetHubOnContext(context.Background(), sentry.CurrentHub().Clone())
+	second := sentry.SetHubOnContext(context.Background(), sentry.CurrentHub().Clone())
+
+	logger.LogInfoCtx(first, "cache miss", "key", "user:1")
+	logger.LogCtx(first, New("first"))
+	logger.LogCtx(second, New("second"))
+
+	events := transport.Events()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	if len(events[0].Breadcrumbs) != 1 || events[0].Breadcrumbs[0].Message != "cache miss" {
+		t.Fatalf("expected the first event to carry the breadcrumb, got %v", events[0].Breadcrumbs)
+	}
+	if events[0].Breadcrumbs[0].Data["key"] != "user:1" {
+		t.Errorf("expected breadcrumb data to hold the metadata, got %v", events[0].Breadcrumbs[0].Data)
+	}
+
+	if len(events[1].Breadcrumbs) != 0 {
+		t.Errorf("expected the second event to carry no breadcrumbs, got %v", events[1].Breadcrumbs)
+	}
+}
+
+func TestEchoMiddlewareReportsEachRequestWithItsOwnScope(t *testing.T) {
+	transport := initFakeSentry(t)
+
+	e := echo.New()
+	e.Use(GetEchoLoggerMiddleware(SentryLogger{}))
+	e.GET("/users/:id", func(c echo.Context) error {
+		return New("user not found").WithKind(NotFound)
+	})
+
+	for _, userID := range []string{"alice", "bob"} {
+		req := httptest.NewRequest(http.MethodGet, "/users/"+userID+"?verbose=1", nil)
+		req.Header.Set("X-User-Id", userID)
+		req.Header.Set("Authorization", "Bearer secret")
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	events := transport.Events()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	for i, userID := range []string{"alice", "bob"} {
+		event := events[i]
+		if event.User.ID != userID {
+			t.Errorf("expected event %d to have user %s, got %s", i, userID, event.User.ID)
+		}
+
+		if event.Request == nil {
+			t.Fatalf("expected event %d to have a request", i)
+		}
+		if expected := "http://example.com/users/" + userID; event.Request.URL != expected {
+			t.Errorf("expected event %d to have url %s, got %s", i, expected, event.Request.URL)
+		}
+		if event.Request.Headers["Authorization"] != filteredHeaderValue {
+			t.Errorf("expected the authorization header to be filtered, got %q", event.Request.Headers["Authorization"])
+		}
+		if event.Transaction != "/users/:id" {
+			t.Errorf("expected transaction /users/:id, got %s", event.Transaction)
+		}
+	}
+}
+
+func TestGRPCInterceptorReportsEachCallWithItsOwnScope(t *testing.T) {
+	transport := initFakeSentry(t)
+
+	interceptor := GRPCInterceptors{Logger: SentryLogger{}}.UnaryInterceptor()
+	handler := func(context.Context, interface{}) (interface{}, error) {
+		return nil, New("unavailable").WithKind(Unavailable)
+	}
+
+	for _, userID := range []string{"alice", "bob"} {
+		md := metadata.Pairs("x-user-id", userID, "authorization", "secret")
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 4242}})
+		_, _ = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/users.Users/Get"}, handler)
+	}
+
+	events := transport.Events()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	for i, userID := range []string{"alice", "bob"} {
+		event := events[i]
+		if event.User.ID != userID || event.User.IPAddress != "10.0.0.1" {
+			t.Errorf("expected event %d to have user %s from 10.0.0.1, got %+v", i, userID, event.User)
+		}
+
+		grpcContext, ok := event.Contexts["grpc"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("expected event %d to have a grpc context, got %v", i, event.Contexts)
+		}
+		if grpcContext["method"] != "/users.Users/Get" || grpcContext["peer"] != "10.0.0.1:4242" {
+			t.Errorf("unexpected grpc context %v", grpcContext)
+		}
+		if headers := grpcContext["metadata"].(map[string]string); headers["authorization"] != filteredHeaderValue {
+			t.Errorf("expected the authorization metadata to be filtered, got %q", headers["authorization"])
+		}
+	}
+}
+
+func TestHTTPMiddlewareBuildsSentryHubLazily(t *testing.T) {
+	var scope *sentryRequestScope
+	handler := HTTPMiddleware{}.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope, _ = r.Context().Value(sentryScopeKey{}).(*sentryRequestScope)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if scope == nil {
+		t.Fatal("expected the request context to carry a sentry scope")
+	}
+	if scope.hub != nil {
+		t.Error("expected the sentry hub not to be built for a request that reports nothing")
+	}
+}
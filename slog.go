@@ -0,0 +1,108 @@
+package richerror
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+// LevelFatal is the slog level used for Fatal errors, keep in mind that unlike other loggers slog doesn't exit
+const LevelFatal = slog.Level(12)
+
+// Assert richError implements slog.LogValuer
+var _ slog.LogValuer = &richError{}
+
+// LogValue implements slog.LogValuer. Metadata keys are written as typed attributes and the wrapped errors are
+// written as nested groups (see SlogLogger).
+func (r *richError) LogValue() slog.Value {
+	return r.slogValue(true)
+}
+
+func (r *richError) slogValue(withMetadata bool) slog.Value {
+	fields := r.layerLogFields(withMetadata)
+
+	attrs := make([]slog.Attr, 0, len(fields)+1)
+	for _, field := range fields {
+		attrs = append(attrs, slog.Any(field.key, field.value))
+	}
+
+	switch wrappedError := r.wrappedError.(type) {
+	case nil:
+	case *errorList:
+		causes := make([]slog.Attr, 0, len(wrappedError.errs))
+		for i, err := range wrappedError.errs {
+			causes = append(causes, slog.Attr{Key: strconv.Itoa(i), Value: slogErrorValue(err, true)})
+		}
+		attrs = append(attrs, slog.Attr{Key: "causes", Value: slog.GroupValue(causes...)})
+	default:
+		attrs = append(attrs, slog.Attr{Key: "cause", Value: slogErrorValue(wrappedError, false)})
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+func slogErrorValue(err error, withMetadata bool) slog.Value {
+	if rErr, ok := err.(*richError); ok {
+		return rErr.slogValue(withMetadata)
+	}
+
+	return slog.GroupValue(
+		slog.String("message", err.Error()),
+		slog.String("go_type", fmt.Sprintf("%T", err)),
+	)
+}
+
+// Assert SlogLogger implements ContextErrorLogger
+var _ ContextErrorLogger = SlogLogger{}
+
+// SlogLogger is an ErrorLogger that logs using a slog.Logger. Metadata of errors (and the fields stored in the context
+// given to LogCtx) are written as top level typed attributes and the error itself, including its wrap chain, is
+// written as a group under the "error" key. The group leaves the metadata out, so it's written only once.
+type SlogLogger struct {
+	Logger *slog.Logger
+}
+
+func (s SlogLogger) Log(err error) {
+	s.LogCtx(context.Background(), err)
+}
+
+func (s SlogLogger) LogCtx(ctx context.Context, err error) {
+	metadata, level := errorLogFields(err, FieldsFromContext(ctx))
+
+	attrs := make([]slog.Attr, 0, len(metadata)+1)
+	for _, field := range metadata {
+		attrs = append(attrs, slog.Any(field.key, field.value))
+	}
+	attrs = append(attrs, slog.Attr{Key: "error", Value: slogErrorValue(err, false)})
+
+	s.Logger.LogAttrs(ctx, slogLevel(level), err.Error(), attrs...)
+}
+
+func (s SlogLogger) LogInfo(msg string) {
+	s.Logger.Info(msg)
+}
+
+func (s SlogLogger) LogInfoWithMetadata(msg string, metadata ...interface{}) {
+	fields := metadataLogFields(metadataFromKeyValues(metadata))
+
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, field := range fields {
+		attrs = append(attrs, slog.Any(field.key, field.value))
+	}
+
+	s.Logger.LogAttrs(context.Background(), slog.LevelInfo, msg, attrs...)
+}
+
+func slogLevel(level Level) slog.Level {
+	switch level {
+	case Fatal:
+		return LevelFatal
+	case Warning:
+		return slog.LevelWarn
+	case Info:
+		return slog.LevelInfo
+	default:
+		return slog.LevelError
+	}
+}
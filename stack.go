@@ -0,0 +1,71 @@
+package richerror
+
+import (
+	"runtime"
+	"sync"
+)
+
+// CaptureStackTrace controls whether New captures the full stack trace of where the error has been created. It's
+// disabled by default, use WithStackTrace to capture the stack trace of a single error.
+var CaptureStackTrace = false
+
+// StackTraceDepth is the maximum number of frames captured for each stack trace
+var StackTraceDepth = 32
+
+// stack holds the program counters of a captured stack trace, they're only symbolized when the frames are requested
+// (e.g. when the error is formatted or logged), so capturing a stack trace is cheap.
+type stack struct {
+	pcs []uintptr
+
+	once   sync.Once
+	frames []RuntimeInfo
+}
+
+// captureStack captures the stack trace of its caller, skip is the number of frames to skip above the caller
+func captureStack(skip, depth int) *stack {
+	if depth <= 0 {
+		depth = StackTraceDepth
+	}
+
+	pcs := make([]uintptr, depth)
+	n := runtime.Callers(skip+2, pcs)
+
+	return &stack{pcs: pcs[:n]}
+}
+
+// symbolizedStack returns a stack whose frames are already known, e.g. frames restored from json
+func symbolizedStack(frames []RuntimeInfo) *stack {
+	s := &stack{frames: frames}
+	s.once.Do(func() {})
+
+	return s
+}
+
+// Frames symbolizes the captured program counters, the innermost frame comes first
+func (s *stack) Frames() []RuntimeInfo {
+	if s == nil {
+		return nil
+	}
+
+	s.once.Do(func() {
+		callersFrames := runtime.CallersFrames(s.pcs)
+		for {
+			frame, more := callersFrames.Next()
+			if frame.PC == 0 {
+				break
+			}
+
+			s.frames = append(s.frames, RuntimeInfo{
+				LineNumber:   frame.Line,
+				FileName:     frame.File,
+				FunctionName: frame.Function,
+			})
+
+			if !more {
+				break
+			}
+		}
+	})
+
+	return s.frames
+}
@@ -0,0 +1,75 @@
+package richerror
+
+// Assert ErrorTemplate implements error
+var _ error = &ErrorTemplate{}
+
+// ErrorTemplate describes a class of errors, it's meant to be declared once (e.g. as a package level variable) and
+// used to create errors at call sites. Errors created from a template match it using errors.Is, regardless of their
+// fields or wrapped errors:
+//
+//	var ErrUserNotFound = richerror.Template("user not found", richerror.NotFound, richerror.Warning, UserType)
+//
+//	err := ErrUserNotFound.New(richerror.Metadata{"user_id": id})
+//	errors.Is(err, ErrUserNotFound) // true
+type ErrorTemplate struct {
+	message string
+	kind    Kind
+	level   Level
+	_type   Type
+}
+
+// Template creates a new ErrorTemplate, _type can be nil
+func Template(message string, kind Kind, level Level, _type Type) *ErrorTemplate {
+	return &ErrorTemplate{
+		message: message,
+		kind:    kind,
+		level:   level,
+		_type:   _type,
+	}
+}
+
+// New creates a new error from the template, the error records the runtime info of where New has been called
+func (t *ErrorTemplate) New(fields ...Metadata) *richError {
+	return t.new(nil, fields)
+}
+
+// Wrap creates a new error from the template that wraps the given error, the error records the runtime info of where
+// Wrap has been called
+func (t *ErrorTemplate) Wrap(err error, fields ...Metadata) *richError {
+	return t.new(err, fields)
+}
+
+func (t *ErrorTemplate) new(err error, fields []Metadata) *richError {
+	rErr := newRichError(t.message, 2).
+		WithKind(t.kind).
+		WithLevel(t.level).
+		WithType(t._type)
+	rErr.template = t
+
+	for _, metadata := range fields {
+		rErr = rErr.WithFields(metadata)
+	}
+
+	if err != nil {
+		rErr = rErr.WithError(err)
+	}
+
+	return rErr
+}
+
+// Error returns the message of the template, it allows templates to be used as errors.Is targets
+func (t *ErrorTemplate) Error() string {
+	return t.message
+}
+
+func (t *ErrorTemplate) Kind() Kind {
+	return t.kind
+}
+
+func (t *ErrorTemplate) Level() Level {
+	return t.level
+}
+
+func (t *ErrorTemplate) Type() Type {
+	return t._type
+}
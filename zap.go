@@ -0,0 +1,108 @@
+package richerror
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Assert richError implements zapcore.ObjectMarshaler
+var _ zapcore.ObjectMarshaler = &richError{}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler. Metadata keys are written as typed fields and the wrapped
+// errors are written as nested objects (see ZapLogger).
+func (r *richError) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return r.marshalZapObject(enc, true)
+}
+
+func (r *richError) marshalZapObject(enc zapcore.ObjectEncoder, withMetadata bool) error {
+	for _, field := range r.layerLogFields(withMetadata) {
+		zap.Any(field.key, field.value).AddTo(enc)
+	}
+
+	switch wrappedError := r.wrappedError.(type) {
+	case nil:
+		return nil
+	case *errorList:
+		return enc.AddArray("causes", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for _, err := range wrappedError.errs {
+				if err := arr.AppendObject(zapErrorObject(err, true)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+	default:
+		return enc.AddObject("cause", zapErrorObject(wrappedError, false))
+	}
+}
+
+func zapErrorObject(err error, withMetadata bool) zapcore.ObjectMarshaler {
+	if rErr, ok := err.(*richError); ok {
+		return zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			return rErr.marshalZapObject(enc, withMetadata)
+		})
+	}
+
+	return zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		enc.AddString("message", err.Error())
+		enc.AddString("go_type", fmt.Sprintf("%T", err))
+		return nil
+	})
+}
+
+// Assert ZapLogger implements ContextErrorLogger
+var _ ContextErrorLogger = ZapLogger{}
+
+// ZapLogger is an ErrorLogger that logs using a zap.Logger. Metadata of errors (and the fields stored in the context
+// given to LogCtx) are written as top level typed fields and the error itself, including its wrap chain, is written
+// as a nested object under the "error" key. The nested object leaves the metadata out, so it's written only once.
+type ZapLogger struct {
+	Logger *zap.Logger
+}
+
+func (z ZapLogger) Log(err error) {
+	z.LogCtx(context.Background(), err)
+}
+
+func (z ZapLogger) LogCtx(ctx context.Context, err error) {
+	metadata, level := errorLogFields(err, FieldsFromContext(ctx))
+
+	fields := make([]zap.Field, 0, len(metadata)+1)
+	for _, field := range metadata {
+		fields = append(fields, zap.Any(field.key, field.value))
+	}
+	fields = append(fields, zap.Object("error", zapErrorObject(err, false)))
+
+	if entry := z.Logger.Check(zapLevel(level), err.Error()); entry != nil {
+		entry.Write(fields...)
+	}
+}
+
+func (z ZapLogger) LogInfo(msg string) {
+	z.Logger.Info(msg)
+}
+
+func (z ZapLogger) LogInfoWithMetadata(msg string, metadata ...interface{}) {
+	fields := make([]zap.Field, 0, len(metadata)/2)
+	for _, field := range metadataLogFields(metadataFromKeyValues(metadata)) {
+		fields = append(fields, zap.Any(field.key, field.value))
+	}
+
+	z.Logger.Info(msg, fields...)
+}
+
+func zapLevel(level Level) zapcore.Level {
+	switch level {
+	case Fatal:
+		return zapcore.FatalLevel
+	case Warning:
+		return zapcore.WarnLevel
+	case Info:
+		return zapcore.InfoLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
@@ -0,0 +1,137 @@
+package richerror
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Assert richError implements zerolog.LogObjectMarshaler
+var _ zerolog.LogObjectMarshaler = &richError{}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler. Metadata keys are written as typed fields and the
+// wrapped errors are written as nested objects (see ZerologLogger).
+func (r *richError) MarshalZerologObject(e *zerolog.Event) {
+	zerologRichError{err: r, withMetadata: true}.MarshalZerologObject(e)
+}
+
+// zerologRichError writes RichErrors, the metadata is left out unless withMetadata is set
+type zerologRichError struct {
+	err          *richError
+	withMetadata bool
+}
+
+func (z zerologRichError) MarshalZerologObject(e *zerolog.Event) {
+	for _, field := range z.err.layerLogFields(z.withMetadata) {
+		addZerologField(e, field)
+	}
+
+	switch wrappedError := z.err.wrappedError.(type) {
+	case nil:
+	case *errorList:
+		causes := zerolog.Arr()
+		for _, err := range wrappedError.errs {
+			causes.Object(zerologErrorObject(err, true))
+		}
+		e.Array("causes", causes)
+	default:
+		e.Object("cause", zerologErrorObject(wrappedError, false))
+	}
+}
+
+// zerologSimpleError writes errors that are not RichErrors
+type zerologSimpleError struct {
+	err error
+}
+
+func (s zerologSimpleError) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("message", s.err.Error())
+	e.Str("go_type", fmt.Sprintf("%T", s.err))
+}
+
+func zerologErrorObject(err error, withMetadata bool) zerolog.LogObjectMarshaler {
+	if rErr, ok := err.(*richError); ok {
+		return zerologRichError{err: rErr, withMetadata: withMetadata}
+	}
+
+	return zerologSimpleError{err: err}
+}
+
+func addZerologField(e *zerolog.Event, field logField) {
+	switch value := field.value.(type) {
+	case string:
+		e.Str(field.key, value)
+	case []string:
+		e.Strs(field.key, value)
+	case bool:
+		e.Bool(field.key, value)
+	case int:
+		e.Int(field.key, value)
+	case int64:
+		e.Int64(field.key, value)
+	case float64:
+		e.Float64(field.key, value)
+	case time.Time:
+		e.Time(field.key, value)
+	case time.Duration:
+		e.Dur(field.key, value)
+	case error:
+		e.AnErr(field.key, value)
+	case fmt.Stringer:
+		e.Stringer(field.key, value)
+	default:
+		e.Interface(field.key, value)
+	}
+}
+
+// Assert ZerologLogger implements ContextErrorLogger
+var _ ContextErrorLogger = ZerologLogger{}
+
+// ZerologLogger is an ErrorLogger that logs using a zerolog.Logger. Metadata of errors (and the fields stored in the
+// context given to LogCtx) are written as top level typed fields and the error itself, including its wrap chain, is
+// written as a nested object under the "error" key. The nested object leaves the metadata out, so it's written only
+// once.
+type ZerologLogger struct {
+	Logger zerolog.Logger
+}
+
+func (z ZerologLogger) Log(err error) {
+	z.LogCtx(context.Background(), err)
+}
+
+func (z ZerologLogger) LogCtx(ctx context.Context, err error) {
+	metadata, level := errorLogFields(err, FieldsFromContext(ctx))
+
+	var event *zerolog.Event
+	switch level {
+	case Fatal:
+		event = z.Logger.Fatal()
+	case Warning:
+		event = z.Logger.Warn()
+	case Info:
+		event = z.Logger.Info()
+	default:
+		event = z.Logger.Error()
+	}
+
+	for _, field := range metadata {
+		addZerologField(event, field)
+	}
+
+	event.Object("error", zerologErrorObject(err, false)).Msg(err.Error())
+}
+
+func (z ZerologLogger) LogInfo(msg string) {
+	z.Logger.Info().Msg(msg)
+}
+
+func (z ZerologLogger) LogInfoWithMetadata(msg string, metadata ...interface{}) {
+	event := z.Logger.Info()
+	for _, field := range metadataLogFields(metadataFromKeyValues(metadata)) {
+		addZerologField(event, field)
+	}
+
+	event.Msg(msg)
+}